@@ -0,0 +1,54 @@
+package dao
+
+import (
+	"github.com/GoFurry/gofurry-nav-collector/collector/compliance/models"
+	"github.com/GoFurry/gofurry-nav-collector/common"
+	"github.com/GoFurry/gofurry-nav-collector/common/abstract"
+)
+
+var newComplianceDao = new(complianceDao)
+
+func init() {
+	newComplianceDao.Init()
+}
+
+type complianceDao struct{ abstract.Dao }
+
+func GetComplianceDao() *complianceDao { return newComplianceDao }
+
+func (dao complianceDao) GetList() ([]models.GfnCollectorDomain, common.GFError) {
+	var res []models.GfnCollectorDomain
+	db := dao.Gm.Table(models.TableNameGfnCollectorDomain)
+	db.Find(&res)
+	if err := db.Error; err != nil {
+		return nil, common.NewDaoError(err.Error())
+	}
+	return res, nil
+}
+
+// 保留 count 条合规检查历史记录
+func (dao complianceDao) DeleteByNum(count string) (int64, common.GFError) {
+	sql := `
+		DELETE FROM ` + models.TableNameGfnCollectorLogCompliance + `
+		WHERE id NOT IN (
+		  SELECT id
+		  FROM (
+			SELECT 
+			  id,
+			  ROW_NUMBER() OVER (
+				PARTITION BY name 
+				ORDER BY create_time DESC
+			  ) AS rn
+			FROM ` + models.TableNameGfnCollectorLogCompliance + `
+		  ) AS ranked
+		  WHERE rn <= ?
+		);`
+
+	db := dao.Gm.Table(models.TableNameGfnCollectorLogCompliance)
+	result := db.Exec(sql, count)
+	if err := db.Error; err != nil {
+		return result.RowsAffected, common.NewDaoError(err.Error())
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,89 @@
+package models
+
+// 合规状态
+const (
+	ComplianceCompliant = "compliant" // 签发机构在 CAA 允许范围内且证书覆盖主机名
+	ComplianceViolation = "violation" // 存在不合规项
+	ComplianceUnknown   = "unknown"   // 缺少采集结果或签发机构无法识别
+	ComplianceNoCert    = "nocert"    // 站点没有证书
+)
+
+// CAIssuer 证书签发机构与其 CAA 域名
+type CAIssuer struct {
+	Name     string   // CA 名称
+	Keywords []string // 在证书 Issuer 的 CN / O 中匹配的关键字, 小写
+	Domains  []string // CA 认可的 CAA issuer 域名
+}
+
+// 常见 CA 的 CAA 域名, 同一 CA 的子品牌或代签中间证书都按签发方的 CAA 域名处理
+// 按顺序匹配, 更具体的关键字放在前面
+var CAIssuers = []CAIssuer{
+	{Name: "Let's Encrypt", Keywords: []string{"let's encrypt"}, Domains: []string{"letsencrypt.org"}},
+	{Name: "Google Trust Services", Keywords: []string{"google trust services"}, Domains: []string{"pki.goog", "google.com"}},
+	{Name: "ZeroSSL", Keywords: []string{"zerossl"}, Domains: []string{"sectigo.com", "zerossl.com"}},
+	{Name: "Sectigo", Keywords: []string{"sectigo", "comodo", "usertrust"}, Domains: []string{"sectigo.com", "comodoca.com", "comodo.com", "usertrust.com", "trust-provider.com"}},
+	{Name: "Cloudflare", Keywords: []string{"cloudflare"}, Domains: []string{"digicert.com", "cloudflare.com"}},
+	{Name: "Microsoft", Keywords: []string{"microsoft"}, Domains: []string{"microsoft.com", "digicert.com"}},
+	{Name: "TrustAsia", Keywords: []string{"trustasia"}, Domains: []string{"trustasia.com", "digicert.com", "sectigo.com"}},
+	{Name: "DigiCert", Keywords: []string{"digicert", "geotrust", "rapidssl", "thawte", "symantec", "encryption everywhere"}, Domains: []string{"digicert.com", "geotrust.com", "rapidssl.com", "thawte.com", "symantec.com", "digitalcertvalidation.com"}},
+	{Name: "GlobalSign", Keywords: []string{"globalsign", "alphassl"}, Domains: []string{"globalsign.com"}},
+	{Name: "Amazon", Keywords: []string{"amazon"}, Domains: []string{"amazon.com", "amazontrust.com", "awstrust.com", "amazonaws.com"}},
+	{Name: "Entrust", Keywords: []string{"entrust", "affirmtrust"}, Domains: []string{"entrust.net", "affirmtrust.com"}},
+	{Name: "SSL.com", Keywords: []string{"ssl.com", "ssl corporation"}, Domains: []string{"ssl.com"}},
+	{Name: "GoDaddy", Keywords: []string{"godaddy", "starfield"}, Domains: []string{"godaddy.com", "starfieldtech.com"}},
+	{Name: "Buypass", Keywords: []string{"buypass"}, Domains: []string{"buypass.com", "buypass.no"}},
+	{Name: "Certum", Keywords: []string{"certum", "asseco", "unizeto"}, Domains: []string{"certum.pl", "certum.eu"}},
+	{Name: "Actalis", Keywords: []string{"actalis"}, Domains: []string{"actalis.it"}},
+	{Name: "HARICA", Keywords: []string{"harica", "hellenic academic"}, Domains: []string{"harica.gr"}},
+	{Name: "IdenTrust", Keywords: []string{"identrust"}, Domains: []string{"identrust.com"}},
+	{Name: "Apple", Keywords: []string{"apple"}, Domains: []string{"apple.com"}},
+	{Name: "WoTrus", Keywords: []string{"wotrus"}, Domains: []string{"wotrus.com"}},
+	{Name: "CFCA", Keywords: []string{"cfca", "china financial certification"}, Domains: []string{"cfca.com.cn"}},
+	{Name: "GDCA", Keywords: []string{"gdca", "global digital cybersecurity"}, Domains: []string{"gdca.com.cn"}},
+}
+
+// HTTPRecord HTTP 采集结果中用到的字段, 对应 request:<域名>
+type HTTPRecord struct {
+	Url           string   `json:"url"`
+	StatusCode    int64    `json:"statusCode"`
+	Redirects     []string `json:"redirects"`
+	CertIssuer    string   `json:"certIssuer"`
+	CertIssuerOrg []string `json:"certIssuerOrg"`
+	CertDNSNames  []string `json:"certDNSNames"`
+}
+
+// DNSAudit DNS 安全审计结果中用到的字段, 对应 dns:<域名> 的 AUDIT
+type DNSAudit struct {
+	CAA DNSCAA `json:"caa"`
+}
+
+// DNSCAA 生效的 CAA 记录集, Owner 为空表示没有 CAA
+type DNSCAA struct {
+	Owner     string   `json:"owner"`
+	Issue     []string `json:"issue"`
+	IssueWild []string `json:"issue_wild"`
+}
+
+// DNSRecord DNS 记录中用到的字段, 对应 dns:<域名> 的 CAA
+type DNSRecord struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ComplianceResult 单个站点的证书合规检查结果
+type ComplianceResult struct {
+	Name          string   `json:"name"`            // 站点
+	Host          string   `json:"host"`            // 实际探测的主机名, 有重定向时为最后一跳
+	CertIssuer    string   `json:"cert_issuer"`     // 证书签发机构
+	CertIssuerOrg []string `json:"cert_issuer_org"` // 证书签发组织
+	CertDNSNames  []string `json:"cert_dns_names"`  // 证书绑定域名
+	CA            string   `json:"ca"`              // 识别出的 CA
+	CADomains     []string `json:"ca_domains"`      // CA 的 CAA 域名
+	CAAOwner      string   `json:"caa_owner"`       // CAA 记录所在名称, 为空表示没有 CAA
+	CAAIssue      []string `json:"caa_issue"`       // CAA 允许的签发机构
+	CAAIssueWild  []string `json:"caa_issue_wild"`  // CAA 允许签发通配符证书的机构
+	IssuerAllowed *bool    `json:"issuer_allowed"`  // 签发机构是否被 CAA 允许, 无法判断时为空
+	HostCovered   bool     `json:"host_covered"`    // 证书是否覆盖主机名
+	Status        string   `json:"status"`          // 合规状态
+	Issues        []string `json:"issues"`          // 问题说明
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+const TableNameGfnCollectorDomain = "gfn_collector_domain"
+
+// GfnCollectorDomain mapped from table <gfn_collector_domain>
+type GfnCollectorDomain struct {
+	ID     int64   `gorm:"column:id;type:bigint;primaryKey;comment:域名请求表id" json:"id"`                        // 域名请求表id
+	Name   string  `gorm:"column:name;type:character varying(255);not null;comment:域名" json:"name"`           // 域名
+	Proxy  string  `gorm:"column:proxy;type:character varying(4);not null;comment:是否需要代理加速 1 0" json:"proxy"` // 是否需要代理加速 1 0
+	Prefix *string `gorm:"column:prefix;type:character varying(255);comment:是否有前缀" json:"prefix"`             // 是否有前缀
+	TLS    string  `gorm:"column:tls;type:character varying(4);not null;comment:是否 https 1 0" json:"tls"`     // 是否 https 1 0
+}
+
+// TableName GfnCollectorDomain's table name
+func (*GfnCollectorDomain) TableName() string {
+	return TableNameGfnCollectorDomain
+}
+
+const TableNameGfnCollectorLogCompliance = "gfn_collector_log_compliance"
+
+// GfnCollectorLogCompliance mapped from table <gfn_collector_log_compliance>
+type GfnCollectorLogCompliance struct {
+	ID         int64     `gorm:"column:id;type:bigint;primaryKey;comment:证书合规日志表 id" json:"id"`                                                   // 证书合规日志表 id
+	Name       string    `gorm:"column:name;type:character varying(255);not null;comment:域名" json:"name"`                                         // 域名
+	Issuer     *string   `gorm:"column:issuer;type:character varying(255);comment:证书签发机构" json:"issuer"`                                          // 证书签发机构
+	Result     *string   `gorm:"column:result;type:json;comment:合规检查结果" json:"result"`                                                            // 合规检查结果
+	Status     string    `gorm:"column:status;type:character varying(20);not null;comment:合规状态 compliant violation unknown nocert" json:"status"` // 合规状态 compliant violation unknown nocert
+	CreateTime time.Time `gorm:"column:create_time;type:int;type:unsigned;not null;autoCreateTime;comment:检查时间" json:"createTime"`                // 检查时间
+}
+
+// TableName GfnCollectorLogCompliance's table name
+func (*GfnCollectorLogCompliance) TableName() string {
+	return TableNameGfnCollectorLogCompliance
+}
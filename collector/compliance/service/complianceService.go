@@ -0,0 +1,390 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/compliance/dao"
+	"github.com/GoFurry/gofurry-nav-collector/collector/compliance/models"
+	"github.com/GoFurry/gofurry-nav-collector/common/log"
+	cs "github.com/GoFurry/gofurry-nav-collector/common/service"
+	"github.com/GoFurry/gofurry-nav-collector/common/util"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+)
+
+// 签发机构列表, 配置中的 CA 优先于内置列表
+var caIssuers = initCAIssuers()
+
+// ============== 证书合规 - 初始化部分 ==============
+
+// 初始化
+func InitComplianceOnStart() {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error(fmt.Sprintf("receive InitComplianceOnStart recover: %v", err))
+		}
+	}()
+	fmt.Println("Compliance 模块初始化开始...")
+
+	//初始化后执行一次 ParseCompliance
+	go ParseCompliance()
+	// 定时任务执行 ParseCompliance
+	cs.AddCronJob(time.Duration(env.GetServerConfig().Collector.Compliance.ComplianceInterval)*time.Hour, ParseCompliance)
+
+	fmt.Println("Compliance 模块初始化结束...")
+}
+
+func initCAIssuers() []models.CAIssuer {
+	var issuers []models.CAIssuer
+	for _, conf := range env.GetServerConfig().Collector.Compliance.CAIssuers {
+		issuer := models.CAIssuer{Name: conf.Name, Domains: conf.Domains}
+		for _, keyword := range conf.Keywords {
+			issuer.Keywords = append(issuer.Keywords, strings.ToLower(keyword))
+		}
+		issuers = append(issuers, issuer)
+	}
+	return append(issuers, models.CAIssuers...)
+}
+
+// ============== 证书合规 - 执行部分 ==============
+
+// 执行 ParseCompliance
+// 只读取 HTTP 和 DNS 模块写入 redis 的最新结果, 不发起网络请求
+func ParseCompliance() {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error(fmt.Sprintf("receive ParseCompliance recover: %v", err))
+		}
+	}()
+
+	siteList, err := dao.GetComplianceDao().GetList()
+	if err != nil {
+		log.Error("Compliance 获取站点列表失败: " + err.GetMsg())
+		return
+	}
+	if len(siteList) < 1 {
+		log.Info("Compliance 站点列表为空")
+		return
+	}
+
+	log.Info("证书合规检查开始")
+	violations := 0
+	for _, site := range siteList {
+		var siteName string
+		if site.Prefix != nil {
+			siteName = *site.Prefix + site.Name
+		} else {
+			siteName = site.Name
+		}
+		res := checkCompliance(siteName)
+		if res.Status == models.ComplianceViolation {
+			violations++
+			log.Info(siteName, " 证书不合规: ", strings.Join(res.Issues, "; "))
+		}
+		saveCompliance(res)
+	}
+	log.Info(fmt.Sprintf("证书合规检查结束, 共 %d 个站点, %d 个不合规", len(siteList), violations))
+
+	// 每个域名仅保留 100 条合规检查记录
+	count, deleteErr := dao.GetComplianceDao().DeleteByNum(env.GetServerConfig().Collector.Compliance.LogCount)
+	if deleteErr != nil {
+		log.Error("删除多余Compliance记录失败: ", deleteErr.GetMsg())
+	} else {
+		log.Info("删除多余Compliance记录成功, 共删除: ", count)
+	}
+}
+
+// ============== 证书合规 - 存储部分 ==============
+
+func saveCompliance(res models.ComplianceResult) {
+	resultJson, _ := json.Marshal(res)
+	resultRecord := string(resultJson)
+
+	// 结果储存回 redis, 每个站点一条
+	if gfError := cs.Set("compliance:"+res.Name, resultRecord); gfError != nil {
+		log.Error("存储合规检查结果失败: ", gfError.GetMsg())
+	}
+
+	newRecord := models.GfnCollectorLogCompliance{
+		ID:         util.GenerateId(),
+		Name:       res.Name,
+		Result:     &resultRecord,
+		Status:     res.Status,
+		CreateTime: time.Now(),
+	}
+	if res.CertIssuer != "" {
+		newRecord.Issuer = &res.CertIssuer
+	}
+	if daoErr := dao.GetComplianceDao().Add(&newRecord); daoErr != nil {
+		log.Error("添加Compliance检查结果到数据库失败: ", daoErr.GetMsg())
+	}
+}
+
+// ============== 证书合规 - 检查部分 ==============
+
+// 对比站点证书的签发机构与 CAA, 并检查证书是否覆盖主机名
+func checkCompliance(siteName string) models.ComplianceResult {
+	res := models.ComplianceResult{
+		Name:         siteName,
+		Host:         siteName,
+		CADomains:    []string{},
+		CAAIssue:     []string{},
+		CAAIssueWild: []string{},
+		Issues:       []string{},
+		Status:       models.ComplianceUnknown,
+	}
+
+	httpRecord, ok := loadHTTPRecord(siteName)
+	if !ok {
+		res.Issues = append(res.Issues, "no http result")
+		return res
+	}
+	if httpRecord.StatusCode == 0 {
+		res.Issues = append(res.Issues, "http request failed")
+		return res
+	}
+	res.Host = probedHost(httpRecord, siteName)
+	res.CertIssuer = httpRecord.CertIssuer
+	res.CertIssuerOrg = httpRecord.CertIssuerOrg
+	res.CertDNSNames = httpRecord.CertDNSNames
+	if res.CertIssuer == "" && len(res.CertIssuerOrg) == 0 && len(res.CertDNSNames) == 0 {
+		res.Status = models.ComplianceNoCert
+		return res
+	}
+
+	violation := false
+	unknown := false
+
+	// 证书是否覆盖主机名
+	res.HostCovered = coversHost(res.CertDNSNames, res.Host)
+	if !res.HostCovered {
+		violation = true
+		res.Issues = append(res.Issues, fmt.Sprintf("certificate names [%s] do not cover %s", strings.Join(res.CertDNSNames, ", "), res.Host))
+	}
+
+	// 签发机构是否被 CAA 允许
+	if ca := matchCAIssuer(res.CertIssuer, res.CertIssuerOrg); ca != nil {
+		res.CA = ca.Name
+		res.CADomains = ca.Domains
+	}
+	caa, caaOK := loadCAA(res.Host, siteName)
+	switch {
+	case !caaOK && !hostWithin(res.Host, siteName):
+		// 跳到了其他域名 (SSO / 停放页等), 没有该域名的 CAA, 不能拿本站的 CAA 对比
+		unknown = true
+		res.Issues = append(res.Issues, "redirected off-site to "+res.Host)
+	case !caaOK:
+		unknown = true
+		res.Issues = append(res.Issues, "no dns result")
+	case caa.Owner == "":
+		// 没有 CAA 时任何 CA 都可以签发
+		allowed := true
+		res.IssuerAllowed = &allowed
+	default:
+		res.CAAOwner = caa.Owner
+		res.CAAIssue = caa.Issue
+		res.CAAIssueWild = caa.IssueWild
+		if res.CA == "" {
+			unknown = true
+			res.Issues = append(res.Issues, fmt.Sprintf("issuer %q is not in the CA list", issuerLabel(res.CertIssuer, res.CertIssuerOrg)))
+			break
+		}
+		allowed := issuerAllowed(res.CADomains, res.CertDNSNames, caa.Issue, caa.IssueWild)
+		res.IssuerAllowed = &allowed
+		if !allowed {
+			violation = true
+			res.Issues = append(res.Issues, fmt.Sprintf("certificate issued by %s (%s) but CAA at %s only allows [%s]",
+				res.CA, strings.Join(res.CADomains, ", "), caa.Owner, strings.Join(permittedLabel(caa.Issue, caa.IssueWild), ", ")))
+		}
+	}
+
+	switch {
+	case violation:
+		res.Status = models.ComplianceViolation
+	case unknown:
+		res.Status = models.ComplianceUnknown
+	default:
+		res.Status = models.ComplianceCompliant
+	}
+	return res
+}
+
+// 读取 HTTP 模块的采集结果
+func loadHTTPRecord(siteName string) (models.HTTPRecord, bool) {
+	var record models.HTTPRecord
+	value, err := cs.GetString("request:" + siteName)
+	if err != nil || value == "" {
+		return record, false
+	}
+	if jsonErr := json.Unmarshal([]byte(value), &record); jsonErr != nil {
+		log.Error(siteName, " 解析HTTP采集结果失败: ", jsonErr)
+		return record, false
+	}
+	return record, true
+}
+
+// 证书来自最后一跳, 有重定向时取最后一跳的主机名
+func probedHost(record models.HTTPRecord, siteName string) string {
+	target := record.Url
+	if n := len(record.Redirects); n > 0 {
+		target = record.Redirects[n-1]
+	}
+	if u, err := url.Parse(target); err == nil && u.Hostname() != "" {
+		return strings.ToLower(u.Hostname())
+	}
+	return siteName
+}
+
+// 读取 DNS 模块的 CAA, 优先使用安全审计中按 RFC 8659 向上查找得到的记录集
+// 主机名没有 DNS 结果时, 只有主机名是站点本身或其子域名才回退到站点的 CAA
+func loadCAA(host string, siteName string) (models.DNSCAA, bool) {
+	names := []string{host}
+	if hostWithin(host, siteName) {
+		names = uniqueNames(host, siteName)
+	}
+	for _, name := range names {
+		fields, err := cs.HMGet("dns:"+name, "AUDIT", "CAA")
+		if err != nil || len(fields) < 2 {
+			continue
+		}
+		if value, ok := fields[0].(string); ok && value != "" {
+			var audit models.DNSAudit
+			if json.Unmarshal([]byte(value), &audit) == nil {
+				return audit.CAA, true
+			}
+		}
+		if value, ok := fields[1].(string); ok && value != "" {
+			var records []models.DNSRecord
+			if json.Unmarshal([]byte(value), &records) != nil {
+				continue
+			}
+			// 只有该名称自身的记录, 没有向上查找
+			var caa models.DNSCAA
+			for _, rec := range records {
+				parts := strings.SplitN(rec.Value, " ", 3)
+				if len(parts) < 3 {
+					continue
+				}
+				caa.Owner = rec.Name
+				issuer := strings.ToLower(strings.TrimSpace(strings.SplitN(parts[2], ";", 2)[0]))
+				switch strings.ToLower(parts[1]) {
+				case "issue":
+					caa.Issue = append(caa.Issue, issuer)
+				case "issuewild":
+					caa.IssueWild = append(caa.IssueWild, issuer)
+				}
+			}
+			return caa, true
+		}
+	}
+	return models.DNSCAA{}, false
+}
+
+// 按 Issuer 的 CN 和 O 匹配 CA
+func matchCAIssuer(issuer string, orgs []string) *models.CAIssuer {
+	label := strings.ToLower(issuerLabel(issuer, orgs))
+	for i, ca := range caIssuers {
+		for _, keyword := range ca.Keywords {
+			if strings.Contains(label, keyword) {
+				return &caIssuers[i]
+			}
+		}
+	}
+	return nil
+}
+
+func issuerLabel(issuer string, orgs []string) string {
+	return strings.TrimSpace(strings.Join(append(append([]string{}, orgs...), issuer), " "))
+}
+
+// 按 RFC 8659 判断签发是否被允许
+// 通配符证书优先看 issuewild, 没有 issuewild 时看 issue; 证书里的每个名称都必须被允许
+func issuerAllowed(caDomains []string, dnsNames []string, issue []string, issueWild []string) bool {
+	hasWildcard, hasPlain := false, len(dnsNames) == 0
+	for _, name := range dnsNames {
+		if strings.HasPrefix(name, "*.") {
+			hasWildcard = true
+		} else {
+			hasPlain = true
+		}
+	}
+	if hasPlain && len(issue) > 0 && !permits(issue, caDomains) {
+		return false
+	}
+	if hasWildcard {
+		property := issueWild
+		if len(property) == 0 {
+			property = issue
+		}
+		if len(property) > 0 && !permits(property, caDomains) {
+			return false
+		}
+	}
+	return true
+}
+
+// 记录集中是否有 CA 的域名, 空字符串表示禁止签发, 不匹配任何 CA
+func permits(property []string, caDomains []string) bool {
+	for _, value := range property {
+		for _, domain := range caDomains {
+			if value != "" && value == domain {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func permittedLabel(issue []string, issueWild []string) []string {
+	var values []string
+	for _, v := range append(append([]string{}, issue...), issueWild...) {
+		if v == "" {
+			v = ";"
+		}
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// 证书名称是否覆盖主机名, 通配符只匹配最左边的一级
+func coversHost(dnsNames []string, host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, name := range dnsNames {
+		name = strings.TrimSuffix(strings.ToLower(name), ".")
+		if name == host {
+			return true
+		}
+		if strings.HasPrefix(name, "*.") {
+			idx := strings.Index(host, ".")
+			if idx > 0 && host[idx+1:] == name[2:] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func uniqueNames(names ...string) []string {
+	var res []string
+	seen := make(map[string]struct{})
+	for _, name := range names {
+		if _, ok := seen[name]; ok || name == "" {
+			continue
+		}
+		seen[name] = struct{}{}
+		res = append(res, name)
+	}
+	return res
+}
+
+// host 是否为 domain 本身或其子域名
+func hostWithin(host string, domain string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
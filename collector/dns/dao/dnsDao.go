@@ -4,6 +4,7 @@ import (
 	"github.com/GoFurry/gofurry-nav-collector/collector/dns/models"
 	"github.com/GoFurry/gofurry-nav-collector/common"
 	"github.com/GoFurry/gofurry-nav-collector/common/abstract"
+	"gorm.io/gorm"
 )
 
 var newDNSDao = new(dnsDao)
@@ -26,6 +27,48 @@ func (dao dnsDao) GetList() ([]models.GfnCollectorDomain, common.GFError) {
 	return res, nil
 }
 
+// 获取域名最近一次的采集记录, 没有时返回 nil
+func (dao dnsDao) GetLatest(name string) (*models.GfnCollectorLogDn, common.GFError) {
+	var res []models.GfnCollectorLogDn
+	db := dao.Gm.Table(models.TableNameGfnCollectorLogDn).Where("name = ?", name).Order("create_time DESC").Limit(1).Find(&res)
+	if err := db.Error; err != nil {
+		return nil, common.NewDaoError(err.Error())
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+	return &res[0], nil
+}
+
+// 批量写入变更记录
+func (dao dnsDao) AddChanges(changes []models.GfnCollectorDNSChange) common.GFError {
+	if len(changes) == 0 {
+		return nil
+	}
+	db := dao.Gm.Table(models.TableNameGfnCollectorDNSChange).Create(&changes)
+	if err := db.Error; err != nil {
+		return common.NewDaoError(err.Error())
+	}
+	return nil
+}
+
+// 替换域名某个检查项的发现, 表中只保留最近一次检查的结果
+func (dao dnsDao) ReplaceFindings(name string, check string, findings []models.GfnCollectorDNSFinding) common.GFError {
+	err := dao.Gm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("name = ? AND check_type = ?", name, check).Delete(&models.GfnCollectorDNSFinding{}).Error; err != nil {
+			return err
+		}
+		if len(findings) == 0 {
+			return nil
+		}
+		return tx.Create(&findings).Error
+	})
+	if err != nil {
+		return common.NewDaoError(err.Error())
+	}
+	return nil
+}
+
 // 保留 count 条request历史记录
 func (dao dnsDao) DeleteByNum(count string) (int64, common.GFError) {
 	sql := `
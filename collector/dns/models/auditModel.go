@@ -0,0 +1,74 @@
+package models
+
+// 检查项
+const (
+	FindingCheckAXFR      = "axfr"           // 区域传送
+	FindingCheckWildcard  = "wildcard"       // 泛解析
+	FindingCheckRecursion = "open_recursion" // 权威服务器开放递归
+	FindingCheckCAA       = "caa"            // CAA 缺失或配置错误
+)
+
+// 安全审计的全部检查项, 写入发现表时按检查项替换
+var AuditChecks = []string{FindingCheckAXFR, FindingCheckWildcard, FindingCheckRecursion, FindingCheckCAA}
+
+// 检查开放递归时查询的区域外名称
+const RecursionProbeName = "www.iana.org."
+
+// CAA 标签, RFC 8659 / RFC 8657 / RFC 9495
+const (
+	CAATagIssue     = "issue"
+	CAATagIssueWild = "issuewild"
+	CAATagIodef     = "iodef"
+)
+
+// 已知的 CAA 标签, 带 critical 标志的未知标签会导致 CA 拒绝签发
+var CAAKnownTags = map[string]struct{}{
+	CAATagIssue:     {},
+	CAATagIssueWild: {},
+	CAATagIodef:     {},
+	"issuemail":     {},
+	"issuevmc":      {},
+	"contactemail":  {},
+	"contactphone":  {},
+}
+
+// AXFRCheck 单个权威服务器的区域传送检查
+type AXFRCheck struct {
+	Name     string `json:"name"`     // NS 主机名
+	IP       string `json:"ip"`       // 查询的 IP
+	Exposed  bool   `json:"exposed"`  // 允许区域传送
+	Records  int    `json:"records"`  // 传送得到的记录数
+	Error    string `json:"error"`    // 拒绝或失败原因
+	Complete bool   `json:"complete"` // 得到了明确结果 (传送成功或被拒绝), 超时等网络错误为 false
+}
+
+// RecursionCheck 单个权威服务器的开放递归检查
+type RecursionCheck struct {
+	Name     string `json:"name"`     // NS 主机名
+	IP       string `json:"ip"`       // 查询的 IP
+	Open     bool   `json:"open"`     // 为区域外名称提供递归
+	Error    string `json:"error"`    // 查询失败原因
+	Complete bool   `json:"complete"` // 得到了应答
+}
+
+// CAAResult 生效的 CAA 记录
+type CAAResult struct {
+	Owner      string   `json:"owner"`      // CAA 记录所在的名称, 按 RFC 8659 向上查找
+	Records    []string `json:"records"`    // 原始记录
+	Issue      []string `json:"issue"`      // 允许签发的 CA, 空字符串表示禁止签发
+	IssueWild  []string `json:"issue_wild"` // 允许签发通配符证书的 CA
+	Iodef      []string `json:"iodef"`      // 违规报告地址
+	Consistent bool     `json:"consistent"` // 各权威服务器的 CAA 一致
+}
+
+// AuditResult DNS 安全审计结果
+type AuditResult struct {
+	Zone            string           `json:"zone"`             // 区域
+	AXFR            []AXFRCheck      `json:"axfr"`             // 区域传送
+	Recursion       []RecursionCheck `json:"recursion"`        // 开放递归
+	Wildcard        bool             `json:"wildcard"`         // 存在泛解析
+	WildcardAnswers []string         `json:"wildcard_answers"` // 随机名称得到的应答
+	CAA             CAAResult        `json:"caa"`              // CAA
+	Findings        []Finding        `json:"findings"`         // 发现
+	Skipped         []string         `json:"skipped"`          // 未能完成的检查项, 发现表中保留上次的结果
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// NameserverCheck 单个权威服务器的检查结果
+type NameserverCheck struct {
+	Name      string        `json:"name"`      // NS 主机名
+	IP        string        `json:"ip"`        // 查询的 IP
+	Reachable bool          `json:"reachable"` // 是否有应答
+	Lame      bool          `json:"lame"`      // 跛脚委派: 有应答但不是该区域的权威
+	Reason    string        `json:"reason"`    // 异常原因
+	Serial    uint32        `json:"serial"`    // SOA 序列号
+	RTT       time.Duration `json:"rtt"`       // SOA 查询耗时
+	NS        []string      `json:"ns"`        // 该服务器返回的 NS 集合
+	Answers   []string      `json:"answers"`   // 该服务器返回的 A / AAAA
+}
+
+// DelegationResult 区域委派健康检查结果
+type DelegationResult struct {
+	Zone              string            `json:"zone"`               // 区域
+	Parent            string            `json:"parent"`             // 上级区域
+	ParentNS          []string          `json:"parent_ns"`          // 上级区域委派的 NS
+	ChildNS           []string          `json:"child_ns"`           // 区域自身声明的 NS
+	Servers           []NameserverCheck `json:"servers"`            // 各权威服务器检查结果
+	SerialConsistent  bool              `json:"serial_consistent"`  // SOA 序列号一致
+	AnswersConsistent bool              `json:"answers_consistent"` // 各服务器应答一致
+	NSConsistent      bool              `json:"ns_consistent"`      // 上级与区域自身 NS 一致
+	MissingInChild    []string          `json:"missing_in_child"`   // 上级有但区域没有的 NS
+	ExtraInChild      []string          `json:"extra_in_child"`     // 区域有但上级没有的 NS
+	Issues            []string          `json:"issues"`             // 问题汇总
+	Healthy           bool              `json:"healthy"`            // 没有发现问题
+}
@@ -7,19 +7,29 @@ import (
 )
 
 type DNSRecord struct {
-	Type         string        `json:"type"`          // 记录类型，如 A/AAAA/MX 等
-	Value        string        `json:"value"`         // 记录值，如 IP / 域名
-	TTL          uint32        `json:"ttl"`           // TTL 值
-	DNSSEC       bool          `json:"dnssec"`        // 是否启用 DNSSEC
-	ASN          string        `json:"asn"`           // IP 所属 ASN
-	Country      string        `json:"country"`       // IP 国家
-	City         string        `json:"city"`          // IP 城市
-	ProviderType string        `json:"provider_type"` // 类型判定：CDN / Origin
-	ISP          string        `json:"isp"`           // ISP 名称
-	Duration     time.Duration `json:"duration"`      // 查询耗时
-	Children     []DNSRecord   `json:"children"`      // 子记录（递归查询产生）
-	ReversePTR   string        `json:"reverse_ptr"`   // 反向 PTR
-	Hijacked     bool          `json:"hijacked"`      // 劫持检测标记
+	Type         string            `json:"type"`          // 记录类型，如 A/AAAA/MX 等
+	Value        string            `json:"value"`         // 记录值，如 IP / 域名
+	TTL          uint32            `json:"ttl"`           // TTL 值
+	AuthTTL      uint32            `json:"auth_ttl"`      // 权威服务器上的原始 TTL, 解析器返回的是缓存剩余值
+	DNSSEC       bool              `json:"dnssec"`        // DNSSEC 验证通过
+	ASN          string            `json:"asn"`           // IP 所属 ASN
+	Country      string            `json:"country"`       // IP 国家
+	City         string            `json:"city"`          // IP 城市
+	ProviderType string            `json:"provider_type"` // 服务商分类：CDN / Cloud / Hosting / Origin
+	Provider     string            `json:"provider"`      // 服务商名称, 未识别时为空
+	ProviderHit  string            `json:"provider_hit"`  // 服务商命中依据
+	ISP          string            `json:"isp"`           // ISP 名称
+	Duration     time.Duration     `json:"duration"`      // 查询耗时
+	Resolver     string            `json:"resolver"`      // 使用的解析器
+	Transport    string            `json:"transport"`     // 传输方式 udp / tcp / tls / https
+	RTT          time.Duration     `json:"rtt"`           // 解析器应答耗时
+	Children     []DNSRecord       `json:"children"`      // 子记录（递归查询产生）
+	ReversePTR   string            `json:"reverse_ptr"`   // 反向 PTR
+	Hijacked     bool              `json:"hijacked"`      // 劫持评分达到阈值
+	HijackScore  int               `json:"hijack_score"`  // 劫持评分 0-100
+	HijackReason []string          `json:"hijack_reason"` // 劫持评分依据
+	Name         string            `json:"name"`          // 记录所有者名称
+	Fields       map[string]string `json:"fields"`        // 按类型解析出的字段, 如 SVCB 的 alpn / ech / ipv4hint
 }
 
 // DNSStatistics 统计结果
@@ -31,13 +41,72 @@ type DNSStatistics struct {
 	MaxTime   time.Duration `json:"max_time"`   // 单条最大耗时
 	AvgTime   time.Duration `json:"avg_time"`   // 单条平均耗时
 	TotalTime time.Duration `json:"total_time"` // 查询总耗时
+	RTT       time.Duration `json:"rtt"`        // 解析器应答耗时
+	Records   int           `json:"records"`    // 记录数
 }
 
+// DNSQueryStats 一次采集的统计, 按记录类型和解析器分别汇总
+type DNSQueryStats struct {
+	Total     DNSStatistics            `json:"total"`     // 全部记录类型汇总
+	Types     map[string]DNSStatistics `json:"types"`     // 各记录类型
+	Resolvers map[string]ResolverStats `json:"resolvers"` // 各解析器
+}
+
+// ResolverStats 单个解析器的延迟统计
+type ResolverStats struct {
+	Transport string        `json:"transport"` // 传输方式
+	Queries   int           `json:"queries"`   // 查询次数
+	Failures  int           `json:"failures"`  // 失败次数
+	MinRTT    time.Duration `json:"min_rtt"`   // 最小耗时
+	MaxRTT    time.Duration `json:"max_rtt"`   // 最大耗时
+	AvgRTT    time.Duration `json:"avg_rtt"`   // 平均耗时
+}
+
+// DNS 采集状态
+const (
+	DNSStatusSuccess  = "success"
+	DNSStatusFailure  = "failure"
+	DNSStatusNXDomain = "nxdomain" // 被监控的域名不存在
+)
+
+// DNSResponse 单个记录类型的应答信息
+type DNSResponse struct {
+	Type        string    `json:"type"`         // 记录类型
+	Rcode       string    `json:"rcode"`        // 应答码 NOERROR / NXDOMAIN / SERVFAIL / REFUSED 等
+	AA          bool      `json:"aa"`           // 权威应答
+	AD          bool      `json:"ad"`           // 解析器已完成 DNSSEC 验证
+	RA          bool      `json:"ra"`           // 支持递归
+	Truncated   bool      `json:"truncated"`    // UDP 应答被截断
+	TCPFallback bool      `json:"tcp_fallback"` // 截断后已通过 TCP 重试
+	EDNS        *EDNSInfo `json:"edns"`         // EDNS 信息, 未启用时为空
+	Error       string    `json:"error"`        // 查询失败原因
+}
+
+// EDNSInfo 应答中的 OPT 记录
+type EDNSInfo struct {
+	Version   uint8    `json:"version"`    // EDNS 版本
+	UDPSize   uint16   `json:"udp_size"`   // 解析器声明的 UDP 包大小
+	DO        bool     `json:"do"`         // DNSSEC OK
+	NSID      string   `json:"nsid"`       // 解析器节点标识
+	Cookie    bool     `json:"cookie"`     // 是否携带 DNS Cookie
+	ExtErrors []string `json:"ext_errors"` // 扩展错误 (RFC 8914)
+}
+
+// DNS 变更类型
+const (
+	DNSChangeAdded    = "added"    // 新增记录
+	DNSChangeRemoved  = "removed"  // 删除记录
+	DNSChangeTTL      = "ttl"      // TTL 变化
+	DNSChangeProvider = "provider" // 服务商或分类变化
+	DNSChangeASN      = "asn"      // 所属 ASN 变化
+)
+
 type RecordType struct {
 	Type uint16
 	Name string
 }
 
+// 默认采集的记录类型, 可通过 dns.record_types 配置
 var RecordTypes = []RecordType{
 	{dns.TypeA, "A"},
 	{dns.TypeAAAA, "AAAA"},
@@ -48,10 +117,3 @@ var RecordTypes = []RecordType{
 	{dns.TypeSOA, "SOA"},
 	{dns.TypeCAA, "CAA"},
 }
-
-// CDN 提供商列表，用于检测 IP 是否为 CDN
-var CdnProviders = []string{
-	"Cloudflare", "Akamai", "Fastly", "EdgeCast",
-	"Tencent", "Alibaba", "Baidu", "ChinaCache",
-	"Huawei", "JD", "Kingsoft", "Wangsu", "Sangfor",
-}
@@ -0,0 +1,43 @@
+package models
+
+// 检查项
+const FindingCheckDNSBL = "dnsbl"
+
+// DNSBLCode 黑名单返回码含义
+type DNSBLCode struct {
+	Label  string // 子列表名称
+	Policy bool   // 策略性列入 (如动态地址段), 不代表地址有恶意行为
+}
+
+// 已知黑名单的返回码, 未列出的黑名单只记录原始返回码
+var DNSBLCodes = map[string]map[string]DNSBLCode{
+	"zen.spamhaus.org": {
+		"127.0.0.2":  {Label: "SBL"},
+		"127.0.0.3":  {Label: "SBL CSS"},
+		"127.0.0.4":  {Label: "XBL"},
+		"127.0.0.5":  {Label: "XBL"},
+		"127.0.0.6":  {Label: "XBL"},
+		"127.0.0.7":  {Label: "XBL"},
+		"127.0.0.9":  {Label: "SBL DROP"},
+		"127.0.0.10": {Label: "PBL ISP", Policy: true},
+		"127.0.0.11": {Label: "PBL Spamhaus", Policy: true},
+	},
+}
+
+// DNSBLListing 在单个黑名单中的列入情况
+type DNSBLListing struct {
+	Name   string   `json:"name"`   // 黑名单名称
+	Zone   string   `json:"zone"`   // 黑名单查询域
+	Codes  []string `json:"codes"`  // 返回码, 已知含义时附带子列表名称
+	Policy bool     `json:"policy"` // 只有策略性列入
+	Reason string   `json:"reason"` // TXT 记录中的说明
+}
+
+// DNSBLResult 单个 IP 的黑名单检查结果
+type DNSBLResult struct {
+	IP       string         `json:"ip"`       // IP
+	Sources  []string       `json:"sources"`  // 来源记录, 如 A / MX mail.example.com
+	Listed   bool           `json:"listed"`   // 至少被一个黑名单列入
+	Listings []DNSBLListing `json:"listings"` // 列入的黑名单
+	Errors   []string       `json:"errors"`   // 查询失败或被黑名单拒绝的情况
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// DNSSEC 验证状态
+const (
+	DNSSECSecure        = "secure"        // 从根信任锚验证通过
+	DNSSECInsecure      = "insecure"      // 已证明未签名的委派或使用不支持的算法
+	DNSSECBogus         = "bogus"         // 签名错误、过期或缺少应有的签名
+	DNSSECIndeterminate = "indeterminate" // 查询失败等原因无法判断
+)
+
+// 状态严重程度, 汇总时取最严重的
+var DNSSECSeverity = map[string]int{
+	DNSSECSecure:        0,
+	DNSSECInsecure:      1,
+	DNSSECIndeterminate: 2,
+	DNSSECBogus:         3,
+}
+
+// DNSSECResult 域名的 DNSSEC 验证结果
+type DNSSECResult struct {
+	Status   string                 `json:"status"`   // 汇总状态
+	Reason   string                 `json:"reason"`   // 非 secure 时的原因
+	Warnings []string               `json:"warnings"` // 签名即将过期等警告
+	Chain    []DNSSECZone           `json:"chain"`    // 从根到目标区域的验证链
+	RRsets   map[string]DNSSECRRset `json:"rrsets"`   // 各记录类型应答的验证结果
+}
+
+// DNSSECZone 验证链中的一个区域
+type DNSSECZone struct {
+	Zone       string   `json:"zone"`       // 区域名
+	Status     string   `json:"status"`     // 验证状态
+	Reason     string   `json:"reason"`     // 原因
+	DSTags     []uint16 `json:"ds_tags"`    // 上级区域中的 DS key tag
+	KeyTags    []uint16 `json:"key_tags"`   // 区域 DNSKEY key tag
+	Algorithms []string `json:"algorithms"` // DNSKEY 算法
+}
+
+// DNSSECRRset 单个记录类型的验证结果
+type DNSSECRRset struct {
+	Type       string    `json:"type"`       // 记录类型
+	Status     string    `json:"status"`     // 验证状态
+	Reason     string    `json:"reason"`     // 原因
+	Signer     string    `json:"signer"`     // 签名区域
+	Expiration time.Time `json:"expiration"` // 签名过期时间
+}
+
+// 根区信任锚 DS
+type TrustAnchor struct {
+	KeyTag     uint16
+	Algorithm  uint8
+	DigestType uint8
+	Digest     string
+}
+
+// IANA 发布的根区 KSK (KSK-2017, KSK-2024)
+var RootTrustAnchors = []TrustAnchor{
+	{20326, dns.RSASHA256, dns.SHA256, "E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBB683457104237C7F8EC8D"},
+	{38696, dns.RSASHA256, dns.SHA256, "683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16"},
+}
+
+// 支持验证的签名算法
+var DNSSECAlgorithms = map[uint8]bool{
+	dns.RSASHA1:          true,
+	dns.RSASHA1NSEC3SHA1: true,
+	dns.RSASHA256:        true,
+	dns.RSASHA512:        true,
+	dns.ECDSAP256SHA256:  true,
+	dns.ECDSAP384SHA384:  true,
+	dns.ED25519:          true,
+}
+
+// 支持的 DS 摘要算法
+var DSDigestTypes = map[uint8]bool{
+	dns.SHA1:   true,
+	dns.SHA256: true,
+	dns.SHA384: true,
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// ECSAddress 应答 IP 及归属
+type ECSAddress struct {
+	IP       string `json:"ip"`       // 应答 IP
+	Country  string `json:"country"`  // 国家
+	City     string `json:"city"`     // 城市
+	ASN      string `json:"asn"`      // ASN
+	Provider string `json:"provider"` // 服务商, 未识别时为空
+}
+
+// ECSAnswer 单个客户端网段的应答
+type ECSAnswer struct {
+	Name      string        `json:"name"`      // 网段名称, 如 China Telecom
+	Subnet    string        `json:"subnet"`    // 客户端网段
+	Country   string        `json:"country"`   // 网段所在国家
+	Type      string        `json:"type"`      // 记录类型 A / AAAA
+	Scope     uint8         `json:"scope"`     // 解析器返回的 scope 前缀长度, 0 表示应答与客户端网段无关
+	Answers   []ECSAddress  `json:"answers"`   // 应答 IP
+	Countries []string      `json:"countries"` // 应答 IP 所在国家
+	Local     bool          `json:"local"`     // 应答 IP 都在网段所在国家
+	RTT       time.Duration `json:"rtt"`       // 查询耗时
+	Error     string        `json:"error"`     // 查询失败原因
+}
+
+// ECSResult 各客户端网段的应答对比
+type ECSResult struct {
+	Resolver     string      `json:"resolver"`      // 使用的解析器
+	Answers      []ECSAnswer `json:"answers"`       // 各网段应答
+	Distinct     int         `json:"distinct"`      // 不同应答集合数
+	GeoDependent bool        `json:"geo_dependent"` // 不同网段得到不同应答
+	NotLocal     []string    `json:"not_local"`     // 应答不在本国的网段
+}
@@ -0,0 +1,27 @@
+package models
+
+// 检查发现的严重程度
+const (
+	SeverityInfo     = "info"
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// 严重程度排序, 用于筛选和排序
+var SeverityRank = map[string]int{
+	SeverityInfo:     0,
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// Finding 单条检查发现
+type Finding struct {
+	Check    string `json:"check"`            // 检查项
+	Severity string `json:"severity"`         // 严重程度
+	Target   string `json:"target,omitempty"` // 检查对象, 如 CNAME 目标
+	Message  string `json:"message"`          // 说明
+}
@@ -0,0 +1,45 @@
+package models
+
+// 劫持评分各项权重, 总分 0-100
+const (
+	HijackScorePollution    = 80 // 已知污染 IP
+	HijackScoreSinkhole     = 70 // 已知 sinkhole / 拦截页 IP
+	HijackScoreBogon        = 60 // 保留 / 未分配地址
+	HijackScoreNXDomain     = 50 // 应答码为 NXDOMAIN 却带有记录
+	HijackScoreBaselineNX   = 60 // 可信解析器返回 NXDOMAIN
+	HijackScoreBaselineASN  = 40 // IP 和 ASN 都不在可信解析器的应答中
+	HijackScoreImpossible   = 50 // 污染常见 ASN 且可信解析器没有返回
+	HijackScoreBaselineOnly = 10 // IP 不在可信解析器的应答中, 但 ASN 一致 (多为 CDN 就近调度)
+	HijackScoreMax          = 100
+)
+
+// 保留 / 未分配地址段数据文件
+type BogonFile struct {
+	IPv4 []string `yaml:"ipv4"`
+	IPv6 []string `yaml:"ipv6"`
+}
+
+// 内置的保留地址段, 数据文件未配置或加载失败时使用
+var BogonRanges = []string{
+	// IPv4
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8",
+	"169.254.0.0/16", "172.16.0.0/12", "192.0.0.0/24", "192.0.2.0/24",
+	"192.168.0.0/16", "198.18.0.0/15", "198.51.100.0/24", "203.0.113.0/24",
+	"224.0.0.0/4", "240.0.0.0/4",
+	// IPv6
+	"::/8", "100::/64", "2001:2::/48", "2001:10::/28", "2001:db8::/32",
+	"3ffe::/16", "fc00::/7", "fe80::/10", "fec0::/10", "ff00::/8",
+}
+
+// 已知 sinkhole / 拦截页 IP
+var SinkholeIPs = map[string]string{
+	"127.0.53.53":    "ICANN name collision",
+	"146.112.61.104": "Cisco Umbrella block page",
+	"146.112.61.105": "Cisco Umbrella block page",
+	"146.112.61.106": "Cisco Umbrella block page",
+	"146.112.61.107": "Cisco Umbrella block page",
+	"146.112.61.108": "Cisco Umbrella block page",
+	"146.112.61.110": "Cisco Umbrella block page",
+	"131.253.18.11":  "Microsoft sinkhole",
+	"131.253.18.12":  "Microsoft sinkhole",
+}
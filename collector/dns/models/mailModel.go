@@ -0,0 +1,86 @@
+package models
+
+// 邮件安全检查项
+const (
+	MailCheckSPF    = "spf"
+	MailCheckDMARC  = "dmarc"
+	MailCheckDKIM   = "dkim"
+	MailCheckMTASTS = "mta-sts"
+	MailCheckTLSRPT = "tls-rpt"
+)
+
+// 写入发现表的邮件检查项
+var MailChecks = []string{MailCheckSPF, MailCheckDMARC, MailCheckDKIM, MailCheckMTASTS, MailCheckTLSRPT}
+
+// SPF 单次解析允许的 DNS 查询数 (RFC 7208 4.6.4)
+const (
+	SPFLookupLimit     = 10
+	SPFVoidLookupLimit = 2
+)
+
+// 默认尝试的 DKIM selector
+var DefaultDKIMSelectors = []string{
+	"default", "dkim", "mail", "smtp", "google", "selector1", "selector2",
+	"k1", "k2", "s1", "s2", "mxvault", "everlytickey1", "cm", "zoho", "mandrill",
+}
+
+// MailSecurity 域名的邮件安全配置
+type MailSecurity struct {
+	Domain   string        `json:"domain"`   // 检查的域名
+	MX       []string      `json:"mx"`       // MX 主机
+	SPF      *SPFResult    `json:"spf"`      // SPF
+	DMARC    *DMARCResult  `json:"dmarc"`    // DMARC
+	DKIM     []DKIMResult  `json:"dkim"`     // 找到的 DKIM 密钥
+	MTASTS   *MTASTSResult `json:"mta_sts"`  // MTA-STS
+	TLSRPT   *TLSRPTResult `json:"tls_rpt"`  // TLS-RPT
+	Findings []Finding     `json:"findings"` // 检查发现
+	Skipped  []string      `json:"skipped"`  // 查询失败未能完成的检查项, 发现表中保留上次的结果
+}
+
+// SPFResult SPF 解析结果
+type SPFResult struct {
+	Record      string   `json:"record"`       // 原始记录
+	Mechanisms  []string `json:"mechanisms"`   // 机制列表
+	All         string   `json:"all"`          // all 的限定符 + - ~ ?, 没有时为空
+	Lookups     int      `json:"lookups"`      // 递归展开后的 DNS 查询数
+	VoidLookups int      `json:"void_lookups"` // 没有结果的查询数
+	Includes    []string `json:"includes"`     // include / redirect 的域名
+}
+
+// DMARCResult DMARC 解析结果
+type DMARCResult struct {
+	Record          string   `json:"record"`           // 原始记录
+	Policy          string   `json:"policy"`           // p
+	SubdomainPolicy string   `json:"subdomain_policy"` // sp
+	Percent         int      `json:"percent"`          // pct
+	Rua             []string `json:"rua"`              // 聚合报告地址
+	Ruf             []string `json:"ruf"`              // 失败报告地址
+	ADKIM           string   `json:"adkim"`            // DKIM 对齐模式 r / s
+	ASPF            string   `json:"aspf"`             // SPF 对齐模式 r / s
+}
+
+// DKIMResult 单个 selector 的 DKIM 密钥
+type DKIMResult struct {
+	Selector string `json:"selector"` // selector
+	KeyType  string `json:"key_type"` // k, 默认 rsa
+	KeyBits  int    `json:"key_bits"` // RSA 密钥长度
+	Revoked  bool   `json:"revoked"`  // p 为空表示密钥已吊销
+	Testing  bool   `json:"testing"`  // t=y 测试模式
+}
+
+// MTASTSResult MTA-STS 配置
+type MTASTSResult struct {
+	Record   string   `json:"record"`    // _mta-sts TXT 记录
+	ID       string   `json:"id"`        // 策略 id
+	Mode     string   `json:"mode"`      // enforce / testing / none
+	MX       []string `json:"mx"`        // 策略允许的 MX
+	MaxAge   int      `json:"max_age"`   // 策略缓存时间 (秒)
+	PolicyOK bool     `json:"policy_ok"` // 策略文件获取并解析成功
+	Error    string   `json:"error"`     // 获取策略失败原因
+}
+
+// TLSRPTResult TLS-RPT 配置
+type TLSRPTResult struct {
+	Record string   `json:"record"` // 原始记录
+	Rua    []string `json:"rua"`    // 报告地址
+}
@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// 解析器分组
+const (
+	ResolverGroupForeign       = "foreign"       // 境外公共解析器
+	ResolverGroupDomestic      = "domestic"      // 境内公共解析器
+	ResolverGroupAuthoritative = "authoritative" // 权威服务器
+)
+
+// 解析器传输方式
+const (
+	TransportUDP   = "udp"
+	TransportTCP   = "tcp"
+	TransportTLS   = "tls"   // DNS-over-TLS
+	TransportHTTPS = "https" // DNS-over-HTTPS
+)
+
+// 解析后的解析器
+type Resolver struct {
+	Name       string        // 解析器名称
+	URI        string        // 配置中的原始地址
+	Transport  string        // 传输方式
+	Address    string        // 连接地址 host:port, DoH 为完整 URL
+	Host       string        // 解析器主机名
+	ServerName string        // TLS SNI
+	Bootstrap  string        // 引导 IP, 设置后不再解析解析器主机名
+	Timeout    time.Duration // 查询超时
+}
+
+// 单个解析器的应答
+type ResolverAnswer struct {
+	Resolver        string        `json:"resolver"`         // 解析器名称
+	Address         string        `json:"address"`          // 解析器地址
+	Transport       string        `json:"transport"`        // 传输方式
+	Group           string        `json:"group"`            // 分组
+	Type            string        `json:"type"`             // 记录类型 A / AAAA
+	Answers         []string      `json:"answers"`          // 排序后的应答 IP
+	ASNs            []string      `json:"asns"`             // 应答 IP 所属 ASN
+	RTT             time.Duration `json:"rtt"`              // 查询耗时
+	Error           string        `json:"error"`            // 查询失败原因
+	Divergent       bool          `json:"divergent"`        // 与多数解析器结果不一致
+	Polluted        bool          `json:"polluted"`         // 疑似污染
+	PollutedReasons []string      `json:"polluted_reasons"` // 污染判定依据
+}
+
+// 多解析器对比结果
+type ResolverComparison struct {
+	Answers    []ResolverAnswer `json:"answers"`    // 各解析器应答
+	Consistent bool             `json:"consistent"` // 全部解析器 IP 一致
+	Divergent  []string         `json:"divergent"`  // IP 和 ASN 都与多数不一致的解析器
+	Polluted   []string         `json:"polluted"`   // 疑似污染的解析器
+}
+
+// 已知的 DNS 污染 IP
+var BogusIPs = []string{
+	"4.36.66.178", "8.7.198.45", "37.61.54.158", "46.82.174.68", "59.24.3.173",
+	"64.33.88.161", "64.33.99.47", "64.66.163.251", "65.104.202.252", "65.160.219.113",
+	"66.45.252.237", "72.14.205.99", "72.14.205.104", "78.16.49.15", "93.46.8.89",
+	"128.121.126.139", "159.106.121.75", "169.132.13.103", "192.67.198.6", "202.106.1.2",
+	"202.181.7.85", "203.98.7.65", "203.161.230.171", "207.12.88.98", "208.56.31.43",
+	"209.36.73.33", "209.145.54.50", "209.220.30.174", "211.94.66.147", "213.169.251.35",
+	"216.221.188.182", "216.234.179.13", "243.185.187.39",
+	"::1", "::",
+}
+
+// 污染应答常见的 ASN, 其他解析器都没有返回该 ASN 时视为不可能的应答
+var PollutionASNs = map[uint]string{
+	32934: "Facebook",
+	13414: "Twitter",
+	19679: "Dropbox",
+	36351: "SoftLayer",
+}
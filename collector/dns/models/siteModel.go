@@ -22,21 +22,69 @@ const TableNameGfnCollectorLogDn = "gfn_collector_log_dns"
 
 // GfnCollectorLogDn mapped from table <gfn_collector_log_dns>
 type GfnCollectorLogDn struct {
-	ID         int64     `gorm:"column:id;type:bigint;primaryKey;comment:DNS日志表 id" json:"id"`                                     // DNS日志表 id
-	Name       string    `gorm:"column:name;type:character varying(255);not null;comment:域名" json:"name"`                          // 域名
-	A          *string   `gorm:"column:a;type:json;comment:A记录" json:"a"`                                                          // A记录
-	Aaaa       *string   `gorm:"column:aaaa;type:json;comment:AAAA记录" json:"aaaa"`                                                 // AAAA记录
-	Mx         *string   `gorm:"column:mx;type:json;comment:MX记录" json:"mx"`                                                       // MX记录
-	Ns         *string   `gorm:"column:ns;type:json;comment:NS记录" json:"ns"`                                                       // NS记录
-	Soa        *string   `gorm:"column:soa;type:json;comment:SOA记录" json:"soa"`                                                    // SOA记录
-	Txt        *string   `gorm:"column:txt;type:json;comment:TXT记录" json:"txt"`                                                    // TXT记录
-	Caa        *string   `gorm:"column:caa;type:json;comment:CAA记录" json:"caa"`                                                    // CAA记录
-	Cname      *string   `gorm:"column:cname;type:json;comment:CNAME记录" json:"cname"`                                              // CNAME记录
-	Status     string    `gorm:"column:status;type:character varying(20);not null;comment:采集状态 success failure" json:"status"`     // 采集状态 success failure
-	CreateTime time.Time `gorm:"column:create_time;type:int;type:unsigned;not null;autoCreateTime;comment:采集时间" json:"createTime"` // 采集时间
+	ID         int64     `gorm:"column:id;type:bigint;primaryKey;comment:DNS日志表 id" json:"id"`                                          // DNS日志表 id
+	Name       string    `gorm:"column:name;type:character varying(255);not null;comment:域名" json:"name"`                               // 域名
+	A          *string   `gorm:"column:a;type:json;comment:A记录" json:"a"`                                                               // A记录
+	Aaaa       *string   `gorm:"column:aaaa;type:json;comment:AAAA记录" json:"aaaa"`                                                      // AAAA记录
+	Mx         *string   `gorm:"column:mx;type:json;comment:MX记录" json:"mx"`                                                            // MX记录
+	Ns         *string   `gorm:"column:ns;type:json;comment:NS记录" json:"ns"`                                                            // NS记录
+	Soa        *string   `gorm:"column:soa;type:json;comment:SOA记录" json:"soa"`                                                         // SOA记录
+	Txt        *string   `gorm:"column:txt;type:json;comment:TXT记录" json:"txt"`                                                         // TXT记录
+	Caa        *string   `gorm:"column:caa;type:json;comment:CAA记录" json:"caa"`                                                         // CAA记录
+	Cname      *string   `gorm:"column:cname;type:json;comment:CNAME记录" json:"cname"`                                                   // CNAME记录
+	Records    *string   `gorm:"column:records;type:json;comment:全部记录类型, 按类型分组" json:"records"`                                         // 全部记录类型, 按类型分组
+	Resolvers  *string   `gorm:"column:resolvers;type:json;comment:多解析器对比结果" json:"resolvers"`                                          // 多解析器对比结果
+	Responses  *string   `gorm:"column:responses;type:json;comment:各记录类型的应答码和标志位" json:"responses"`                                     // 各记录类型的应答码和标志位
+	Dnssec     *string   `gorm:"column:dnssec;type:json;comment:DNSSEC验证结果" json:"dnssec"`                                              // DNSSEC验证结果
+	Delegation *string   `gorm:"column:delegation;type:json;comment:委派健康检查结果" json:"delegation"`                                        // 委派健康检查结果
+	Mail       *string   `gorm:"column:mail;type:json;comment:邮件安全检查结果" json:"mail"`                                                    // 邮件安全检查结果
+	Ecs        *string   `gorm:"column:ecs;type:json;comment:各客户端网段的ECS应答" json:"ecs"`                                                  // 各客户端网段的ECS应答
+	Dnsbl      *string   `gorm:"column:dnsbl;type:json;comment:IP黑名单检查结果" json:"dnsbl"`                                                 // IP黑名单检查结果
+	Takeover   *string   `gorm:"column:takeover;type:json;comment:子域名接管检查结果" json:"takeover"`                                           // 子域名接管检查结果
+	Audit      *string   `gorm:"column:audit;type:json;comment:安全审计结果" json:"audit"`                                                    // 安全审计结果
+	Stats      *string   `gorm:"column:stats;type:json;comment:查询统计" json:"stats"`                                                      // 查询统计
+	Status     string    `gorm:"column:status;type:character varying(20);not null;comment:采集状态 success failure nxdomain" json:"status"` // 采集状态 success failure nxdomain
+	CreateTime time.Time `gorm:"column:create_time;type:int;type:unsigned;not null;autoCreateTime;comment:采集时间" json:"createTime"`      // 采集时间
 }
 
 // TableName GfnCollectorLogDn's table name
 func (*GfnCollectorLogDn) TableName() string {
 	return TableNameGfnCollectorLogDn
 }
+
+const TableNameGfnCollectorDNSChange = "gfn_collector_dns_change"
+
+// GfnCollectorDNSChange mapped from table <gfn_collector_dns_change>
+type GfnCollectorDNSChange struct {
+	ID         int64     `gorm:"column:id;type:bigint;primaryKey;comment:DNS变更表 id" json:"id"`                                                // DNS变更表 id
+	Name       string    `gorm:"column:name;type:character varying(255);not null;comment:域名" json:"name"`                                     // 域名
+	Type       string    `gorm:"column:type;type:character varying(20);not null;comment:记录类型" json:"type"`                                    // 记录类型
+	Action     string    `gorm:"column:action;type:character varying(20);not null;comment:变更类型 added removed ttl provider asn" json:"action"` // 变更类型 added removed ttl provider asn
+	Value      string    `gorm:"column:value;type:character varying(1024);not null;comment:记录值" json:"value"`                                 // 记录值
+	OldValue   *string   `gorm:"column:old_value;type:character varying(1024);comment:变更前" json:"oldValue"`                                   // 变更前
+	NewValue   *string   `gorm:"column:new_value;type:character varying(1024);comment:变更后" json:"newValue"`                                   // 变更后
+	CreateTime time.Time `gorm:"column:create_time;type:int;type:unsigned;not null;autoCreateTime;comment:发现时间" json:"createTime"`            // 发现时间
+}
+
+// TableName GfnCollectorDNSChange's table name
+func (*GfnCollectorDNSChange) TableName() string {
+	return TableNameGfnCollectorDNSChange
+}
+
+const TableNameGfnCollectorDNSFinding = "gfn_collector_dns_finding"
+
+// GfnCollectorDNSFinding mapped from table <gfn_collector_dns_finding>
+type GfnCollectorDNSFinding struct {
+	ID         int64     `gorm:"column:id;type:bigint;primaryKey;comment:DNS检查发现表 id" json:"id"`                                                 // DNS检查发现表 id
+	Name       string    `gorm:"column:name;type:character varying(255);not null;comment:域名" json:"name"`                                        // 域名
+	Check      string    `gorm:"column:check_type;type:character varying(50);not null;comment:检查项" json:"check"`                                 // 检查项
+	Severity   string    `gorm:"column:severity;type:character varying(20);not null;comment:严重程度 info low medium high critical" json:"severity"` // 严重程度 info low medium high critical
+	Target     string    `gorm:"column:target;type:character varying(255);not null;comment:检查对象" json:"target"`                                  // 检查对象
+	Message    string    `gorm:"column:message;type:character varying(1024);not null;comment:说明" json:"message"`                                 // 说明
+	CreateTime time.Time `gorm:"column:create_time;type:int;type:unsigned;not null;autoCreateTime;comment:发现时间" json:"createTime"`               // 发现时间
+}
+
+// TableName GfnCollectorDNSFinding's table name
+func (*GfnCollectorDNSFinding) TableName() string {
+	return TableNameGfnCollectorDNSFinding
+}
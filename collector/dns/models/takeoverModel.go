@@ -0,0 +1,44 @@
+package models
+
+// 检查项
+const FindingCheckTakeover = "takeover"
+
+// 子域名接管特征
+type TakeoverSignature struct {
+	Service      string   // 服务名称
+	CNAMEs       []string // CNAME 目标后缀
+	NXDomain     bool     // 目标 NXDOMAIN 时可直接注册同名资源接管
+	Fingerprints []string // 资源已删除时服务返回的页面特征, 命中任意一个即可; 不使用 Web 服务器通用的错误页文字
+}
+
+// 子域名接管特征列表, 参考 can-i-take-over-xyz
+var TakeoverSignatures = []TakeoverSignature{
+	{Service: "GitHub Pages", CNAMEs: []string{"github.io"}, Fingerprints: []string{"There isn't a GitHub Pages site here."}},
+	{Service: "Heroku", CNAMEs: []string{"herokuapp.com", "herokudns.com", "herokussl.com"}, NXDomain: true, Fingerprints: []string{"No such app", "herokucdn.com/error-pages/no-such-app.html"}},
+	{Service: "AWS S3", CNAMEs: []string{"s3.amazonaws.com", "s3-website.amazonaws.com"}, Fingerprints: []string{"NoSuchBucket", "The specified bucket does not exist"}},
+	{Service: "AWS Elastic Beanstalk", CNAMEs: []string{"elasticbeanstalk.com"}, NXDomain: true},
+	{Service: "Azure", CNAMEs: []string{
+		"cloudapp.net", "cloudapp.azure.com", "azurewebsites.net", "blob.core.windows.net", "trafficmanager.net",
+		"azure-api.net", "azurehdinsight.net", "azureedge.net", "azurecontainer.io", "database.windows.net",
+		"azurecr.io", "redis.cache.windows.net", "servicebus.windows.net", "visualstudio.com",
+	}, NXDomain: true},
+	{Service: "Google Cloud Storage", CNAMEs: []string{"storage.googleapis.com"}, Fingerprints: []string{"NoSuchBucket", "The specified bucket does not exist."}},
+	{Service: "Netlify", CNAMEs: []string{"netlify.app", "netlify.com"}},
+	{Service: "Fastly", CNAMEs: []string{"fastly.net"}, Fingerprints: []string{"Fastly error: unknown domain"}},
+	{Service: "Shopify", CNAMEs: []string{"myshopify.com"}, Fingerprints: []string{"Sorry, this shop is currently unavailable."}},
+	{Service: "Ghost", CNAMEs: []string{"ghost.io"}, Fingerprints: []string{"The thing you were looking for is no longer here, or never was"}},
+	{Service: "Pantheon", CNAMEs: []string{"pantheonsite.io"}, Fingerprints: []string{"The gods are wise, but do not know of the site which you seek."}},
+	{Service: "Tumblr", CNAMEs: []string{"domains.tumblr.com"}, Fingerprints: []string{"Whatever you were looking for doesn't currently exist at this address."}},
+	{Service: "WordPress.com", CNAMEs: []string{"wordpress.com"}, Fingerprints: []string{"Do you want to register <em>"}},
+	{Service: "Surge.sh", CNAMEs: []string{"surge.sh"}, Fingerprints: []string{"project not found"}},
+	{Service: "Bitbucket", CNAMEs: []string{"bitbucket.io"}, Fingerprints: []string{"Repository not found"}},
+	{Service: "Read the Docs", CNAMEs: []string{"readthedocs.io"}, Fingerprints: []string{"unknown to Read the Docs"}},
+	{Service: "ReadMe", CNAMEs: []string{"readme.io"}, Fingerprints: []string{"Project doesnt exist... yet!"}},
+	{Service: "Help Scout", CNAMEs: []string{"helpscoutdocs.com"}, Fingerprints: []string{"No settings were found for this company:"}},
+	{Service: "Webflow", CNAMEs: []string{"proxy-ssl.webflow.com", "proxy.webflow.com"}},
+	{Service: "Unbounce", CNAMEs: []string{"unbouncepages.com"}},
+	{Service: "Zendesk", CNAMEs: []string{"zendesk.com"}, Fingerprints: []string{"Help Center Closed"}},
+	{Service: "ngrok", CNAMEs: []string{"ngrok.io"}, Fingerprints: []string{"ngrok.io not found"}},
+	{Service: "Fly.io", CNAMEs: []string{"fly.dev"}, NXDomain: true},
+	{Service: "Vercel", CNAMEs: []string{"vercel.app"}, Fingerprints: []string{"DEPLOYMENT_NOT_FOUND"}},
+}
@@ -0,0 +1,365 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/dns/models"
+	"github.com/GoFurry/gofurry-nav-collector/common/util"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"github.com/miekg/dns"
+	"golang.org/x/net/publicsuffix"
+)
+
+// 权威服务器地址
+type auditServer struct {
+	name string
+	ip   string
+	lame bool
+}
+
+// ============== DNS解析 - 安全审计 ==============
+
+// 对域名所在区域做安全审计: 区域传送、开放递归、泛解析和 CAA
+// 权威服务器沿用委派检查的结果, 不再重复解析
+func auditDNS(domain string, delegation models.DelegationResult) models.AuditResult {
+	res := models.AuditResult{
+		Zone:            delegation.Zone,
+		AXFR:            []models.AXFRCheck{},
+		Recursion:       []models.RecursionCheck{},
+		WildcardAnswers: []string{},
+		Findings:        []models.Finding{},
+		Skipped:         []string{},
+	}
+	addFinding := func(check string, severity string, target string, format string, args ...any) {
+		res.Findings = append(res.Findings, models.Finding{Check: check, Severity: severity, Target: target, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var servers []auditServer
+	for _, s := range delegation.Servers {
+		if s.Reachable {
+			servers = append(servers, auditServer{name: s.Name, ip: s.IP, lame: s.Lame})
+		}
+	}
+
+	if res.Zone == "" {
+		// 委派检查失败, 区域相关的检查都没有做
+		res.Skipped = append(res.Skipped, models.FindingCheckAXFR, models.FindingCheckRecursion, models.FindingCheckWildcard)
+	} else {
+		var auditMu sync.Mutex
+		var auditWG sync.WaitGroup
+		for _, s := range servers {
+			auditWG.Add(1)
+			go func(s auditServer) {
+				defer auditWG.Done()
+				recursion := checkRecursion(s)
+				// 跛脚服务器不是该区域的权威, 不做区域传送
+				var axfr *models.AXFRCheck
+				if !s.lame {
+					check := checkAXFR(res.Zone, s)
+					axfr = &check
+				}
+				auditMu.Lock()
+				defer auditMu.Unlock()
+				res.Recursion = append(res.Recursion, recursion)
+				if axfr != nil {
+					res.AXFR = append(res.AXFR, *axfr)
+				}
+			}(s)
+		}
+		auditWG.Wait()
+		sort.Slice(res.AXFR, func(i, j int) bool { return res.AXFR[i].Name+res.AXFR[i].IP < res.AXFR[j].Name+res.AXFR[j].IP })
+		sort.Slice(res.Recursion, func(i, j int) bool {
+			return res.Recursion[i].Name+res.Recursion[i].IP < res.Recursion[j].Name+res.Recursion[j].IP
+		})
+
+		// 任一服务器超时都不替换发现, 避免暂时的网络问题清掉未处理的发现
+		if !axfrComplete(res.AXFR) {
+			res.Skipped = append(res.Skipped, models.FindingCheckAXFR)
+		}
+		if !recursionComplete(res.Recursion) {
+			res.Skipped = append(res.Skipped, models.FindingCheckRecursion)
+		}
+		for _, c := range res.AXFR {
+			if c.Exposed {
+				addFinding(models.FindingCheckAXFR, models.SeverityHigh, c.Name,
+					"%s (%s) allows zone transfer of %s, %d records exposed", strings.TrimSuffix(c.Name, "."), c.IP, res.Zone, c.Records)
+			}
+		}
+		for _, c := range res.Recursion {
+			if c.Open {
+				addFinding(models.FindingCheckRecursion, models.SeverityHigh, c.Name,
+					"%s (%s) answers recursive queries for names outside its zones", strings.TrimSuffix(c.Name, "."), c.IP)
+			}
+		}
+
+		var wildcardOK bool
+		res.Wildcard, res.WildcardAnswers, wildcardOK = checkWildcard(res.Zone)
+		if !wildcardOK {
+			res.Skipped = append(res.Skipped, models.FindingCheckWildcard)
+		}
+		if res.Wildcard {
+			addFinding(models.FindingCheckWildcard, models.SeverityLow, res.Zone,
+				"wildcard record under %s resolves to %s", res.Zone, strings.Join(res.WildcardAnswers, ", "))
+		}
+	}
+
+	var caaOK bool
+	res.CAA, caaOK = checkCAA(domain, res.Zone, servers, addFinding)
+	if !caaOK {
+		res.Skipped = append(res.Skipped, models.FindingCheckCAA)
+	}
+	return res
+}
+
+// 每个服务器都得到了明确结果, 没有可检查的服务器时视为未完成
+func axfrComplete(checks []models.AXFRCheck) bool {
+	for _, c := range checks {
+		if !c.Complete {
+			return false
+		}
+	}
+	return len(checks) > 0
+}
+
+func recursionComplete(checks []models.RecursionCheck) bool {
+	for _, c := range checks {
+		if !c.Complete {
+			return false
+		}
+	}
+	return len(checks) > 0
+}
+
+// 区域传送是否被明确拒绝: 返回错误应答码, 或不提供 TCP / 直接断开连接
+func axfrRefused(err error) bool {
+	var dnsErr *dns.Error
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// 尝试区域传送
+func checkAXFR(zone string, s auditServer) models.AXFRCheck {
+	check := models.AXFRCheck{Name: s.name, IP: s.ip}
+	m := new(dns.Msg)
+	m.SetAxfr(zone)
+	t := &dns.Transfer{DialTimeout: resolver.Timeout, ReadTimeout: resolver.Timeout, WriteTimeout: resolver.Timeout}
+	ch, err := t.In(m, net.JoinHostPort(s.ip, "53"))
+	if err != nil {
+		check.Error = err.Error()
+		check.Complete = axfrRefused(err)
+		return check
+	}
+	var transferErr error
+	// 通道必须读完, 否则传送协程无法退出
+	for envelope := range ch {
+		if envelope.Error != nil {
+			if transferErr == nil {
+				transferErr = envelope.Error
+				check.Error = envelope.Error.Error()
+			}
+			continue
+		}
+		check.Records += len(envelope.RR)
+	}
+	// 拒绝时不会返回任何记录, 传送中途出错但已拿到记录同样算泄露
+	check.Exposed = check.Records > 0
+	check.Complete = check.Exposed || transferErr == nil || axfrRefused(transferErr)
+	return check
+}
+
+// 向权威服务器查询区域外的名称, 能拿到应答说明开放了递归
+func checkRecursion(s auditServer) models.RecursionCheck {
+	check := models.RecursionCheck{Name: s.name, IP: s.ip}
+	r, _ := parseResolver(env.ResolverConfig{Name: strings.TrimSuffix(s.name, "."), Address: net.JoinHostPort(s.ip, "53")})
+	m := new(dns.Msg)
+	m.SetQuestion(models.RecursionProbeName, dns.TypeA)
+	m.RecursionDesired = true
+	in, _, err := exchange(m, r)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	check.Complete = true
+	check.Open = in.RecursionAvailable && in.Rcode == dns.RcodeSuccess && len(in.Answer) > 0
+	return check
+}
+
+// 查询区域下随机名称, 有应答说明存在泛解析, 查询失败时 ok 为 false
+func checkWildcard(zone string) (bool, []string, bool) {
+	answers := []string{}
+	probe := "gfn-audit-" + strconv.FormatInt(util.GenerateId(), 36) + "." + zone
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		m := new(dns.Msg)
+		m.SetQuestion(probe, qtype)
+		in, _, err := exchange(m, resolver)
+		if err != nil || (in.Rcode != dns.RcodeSuccess && in.Rcode != dns.RcodeNameError) {
+			return false, answers, false
+		}
+		if in.Rcode != dns.RcodeSuccess {
+			continue
+		}
+		for _, rr := range in.Answer {
+			switch v := rr.(type) {
+			case *dns.A:
+				answers = append(answers, v.A.String())
+			case *dns.AAAA:
+				answers = append(answers, v.AAAA.String())
+			case *dns.CNAME:
+				answers = append(answers, "CNAME "+strings.ToLower(strings.TrimSuffix(v.Target, ".")))
+			}
+		}
+	}
+	sort.Strings(answers)
+	answers = uniqueStrings(answers)
+	return len(answers) > 0, answers, true
+}
+
+// 按 RFC 8659 查找生效的 CAA 记录集, 检查配置并对比各权威服务器, 查询失败时 ok 为 false
+func checkCAA(domain string, zone string, servers []auditServer, addFinding func(string, string, string, string, ...any)) (models.CAAResult, bool) {
+	res := models.CAAResult{Records: []string{}, Issue: []string{}, IssueWild: []string{}, Iodef: []string{}, Consistent: true}
+	owner, records, ok := lookupCAA(domain)
+	if !ok {
+		return res, false
+	}
+	if owner == "" {
+		addFinding(models.FindingCheckCAA, models.SeverityLow, domain, "no CAA record for %s or its parents, any CA may issue certificates", domain)
+		return res, true
+	}
+	res.Owner = owner
+	res.Records = caaStrings(records)
+
+	for _, caa := range records {
+		tag := strings.ToLower(caa.Tag)
+		if _, known := models.CAAKnownTags[tag]; !known {
+			if caa.Flag&128 != 0 {
+				addFinding(models.FindingCheckCAA, models.SeverityHigh, owner, "CAA record with unknown critical tag %q, CAs must refuse to issue", caa.Tag)
+			}
+			continue
+		}
+		switch tag {
+		case models.CAATagIssue, models.CAATagIssueWild:
+			issuer, ok := parseCAAIssuer(caa.Value)
+			if !ok {
+				addFinding(models.FindingCheckCAA, models.SeverityMedium, owner, "CAA %s value %q is not a valid issuer domain", tag, caa.Value)
+				continue
+			}
+			if tag == models.CAATagIssue {
+				res.Issue = append(res.Issue, issuer)
+			} else {
+				res.IssueWild = append(res.IssueWild, issuer)
+			}
+		case models.CAATagIodef:
+			res.Iodef = append(res.Iodef, caa.Value)
+		}
+	}
+	if len(res.Issue) == 0 && len(res.IssueWild) > 0 {
+		addFinding(models.FindingCheckCAA, models.SeverityLow, owner, "CAA only restricts wildcard certificates, no issue tag for %s", owner)
+	}
+	if len(res.Iodef) == 0 && (len(res.Issue) > 0 || len(res.IssueWild) > 0) {
+		addFinding(models.FindingCheckCAA, models.SeverityInfo, owner, "CAA has no iodef reporting address")
+	}
+
+	// 记录集在本区域内时, 各权威服务器应返回相同的 CAA
+	if zone == "" || !dns.IsSubDomain(zone, owner) {
+		return res, true
+	}
+	expected := strings.Join(res.Records, "|")
+	for _, s := range servers {
+		if s.lame {
+			continue
+		}
+		r, _ := parseResolver(env.ResolverConfig{Name: strings.TrimSuffix(s.name, "."), Address: net.JoinHostPort(s.ip, "53")})
+		m := new(dns.Msg)
+		m.SetQuestion(owner, dns.TypeCAA)
+		m.RecursionDesired = false
+		in, _, err := exchange(m, r)
+		if err != nil || in.Rcode != dns.RcodeSuccess {
+			continue
+		}
+		var got []*dns.CAA
+		for _, rr := range in.Answer {
+			if caa, ok := rr.(*dns.CAA); ok {
+				got = append(got, caa)
+			}
+		}
+		if strings.Join(caaStrings(got), "|") != expected {
+			res.Consistent = false
+			addFinding(models.FindingCheckCAA, models.SeverityMedium, s.name,
+				"%s (%s) returns CAA [%s], resolver returns [%s]", strings.TrimSuffix(s.name, "."), s.ip,
+				strings.Join(caaStrings(got), ", "), strings.Join(res.Records, ", "))
+		}
+	}
+	return res, true
+}
+
+// 从域名开始逐级向上查找 CAA, 直到可注册域名, 返回第一个非空记录集
+// NXDOMAIN 视为没有记录继续向上; 查询失败或 SERVFAIL 时无法确定生效的记录集, ok 为 false
+func lookupCAA(domain string) (string, []*dns.CAA, bool) {
+	name := strings.TrimSuffix(strings.ToLower(domain), ".")
+	registrable, err := publicsuffix.EffectiveTLDPlusOne(name)
+	if err != nil {
+		registrable = name
+	}
+	for {
+		m := new(dns.Msg)
+		m.SetQuestion(dns.Fqdn(name), dns.TypeCAA)
+		in, _, exErr := exchange(m, resolver)
+		if exErr != nil || (in.Rcode != dns.RcodeSuccess && in.Rcode != dns.RcodeNameError) {
+			return "", nil, false
+		}
+		var records []*dns.CAA
+		for _, rr := range in.Answer {
+			if caa, ok := rr.(*dns.CAA); ok {
+				records = append(records, caa)
+			}
+		}
+		if len(records) > 0 {
+			return dns.Fqdn(name), records, true
+		}
+		if name == registrable {
+			return "", nil, true
+		}
+		idx := strings.Index(name, ".")
+		if idx < 0 {
+			return "", nil, true
+		}
+		name = name[idx+1:]
+	}
+}
+
+// 解析 issue / issuewild 的 CA 域名, ";" 表示禁止签发, 返回空字符串
+func parseCAAIssuer(value string) (string, bool) {
+	issuer := strings.ToLower(strings.TrimSpace(strings.SplitN(value, ";", 2)[0]))
+	if issuer == "" {
+		return "", true
+	}
+	if _, ok := dns.IsDomainName(issuer); !ok || !strings.Contains(issuer, ".") {
+		return "", false
+	}
+	return issuer, true
+}
+
+// CAA 记录转为排序后的文本, 用于对比
+func caaStrings(records []*dns.CAA) []string {
+	values := make([]string, 0, len(records))
+	for _, caa := range records {
+		values = append(values, fmt.Sprintf("%d %s %q", caa.Flag, strings.ToLower(caa.Tag), caa.Value))
+	}
+	sort.Strings(values)
+	return values
+}
+
+// 审计发现按检查项写入发现表, 未能完成的检查项保留上次的发现
+func saveAuditFindings(name string, audit models.AuditResult) {
+	saveGroupedFindings(name, models.AuditChecks, audit.Skipped, audit.Findings, models.SeverityLow)
+}
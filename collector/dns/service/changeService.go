@@ -0,0 +1,214 @@
+package service
+
+import (
+	"encoding/json"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/dns/models"
+	"github.com/GoFurry/gofurry-nav-collector/common/util"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"github.com/miekg/dns"
+)
+
+// ============== DNS解析 - 变更检测 ==============
+
+// 从权威服务器获取各记录类型的原始 TTL, 解析器缓存返回的 TTL 会递减, 不能直接比较
+func fillAuthTTL(domain string, results map[string][]models.DNSRecord) {
+	auth := authoritativeResolver(domain)
+	if auth == nil {
+		return
+	}
+
+	for _, rt := range recordTypes {
+		if len(results[rt.Name]) == 0 {
+			continue
+		}
+		// 按记录的所有者名称查询, SRV 等记录不在域名本身
+		m := new(dns.Msg)
+		m.SetQuestion(dns.Fqdn(orDefault(results[rt.Name][0].Name, domain)), rt.Type)
+		m.RecursionDesired = false
+		in, _, err := exchange(m, auth)
+		if err != nil || !in.Authoritative {
+			continue
+		}
+		for _, rr := range in.Answer {
+			if rr.Header().Rrtype != rt.Type {
+				continue
+			}
+			for i := range results[rt.Name] {
+				results[rt.Name][i].AuthTTL = rr.Header().Ttl
+			}
+			break
+		}
+	}
+}
+
+// 记录类型是否查询失败
+// SERVFAIL / REFUSED 等应答没有 Error 但也拿不到记录; SRV 按 "SRV 服务名" 分别记录应答, 任一失败都不对比
+func typeQueryFailed(rt models.RecordType, responses map[string]models.DNSResponse) bool {
+	keys := []string{rt.Name}
+	if rt.Type == dns.TypeSRV {
+		keys = keys[:0]
+		for _, srv := range env.GetServerConfig().Collector.Dns.SrvNames {
+			keys = append(keys, rt.Name+" "+srv)
+		}
+	}
+	for _, key := range keys {
+		resp, ok := responses[key]
+		if !ok || resp.Error != "" {
+			return true
+		}
+		if resp.Rcode != dns.RcodeToString[dns.RcodeSuccess] && resp.Rcode != dns.RcodeToString[dns.RcodeNameError] {
+			return true
+		}
+	}
+	return false
+}
+
+// 给定记录类型中是否有查询失败的, 未采集的类型不算
+func anyTypeFailed(responses map[string]models.DNSResponse, qtypes ...uint16) bool {
+	for _, rt := range recordTypes {
+		for _, qtype := range qtypes {
+			if rt.Type == qtype && typeQueryFailed(rt, responses) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 与上一次快照对比, 生成变更记录
+// 本次或上次查询失败的记录类型不参与对比, 避免把失败当成记录被删除, 或把恢复当成记录被新增
+func diffDNSSnapshot(name string, prev *models.GfnCollectorLogDn, results map[string][]models.DNSRecord, responses map[string]models.DNSResponse) []models.GfnCollectorDNSChange {
+	changes := []models.GfnCollectorDNSChange{}
+	if prev == nil {
+		return changes
+	}
+	prevRecordMap := snapshotRecords(prev)
+	prevResponses, hasPrevResponses := snapshotResponses(prev)
+	now := time.Now()
+
+	for _, rt := range recordTypes {
+		if typeQueryFailed(rt, responses) || (hasPrevResponses && typeQueryFailed(rt, prevResponses)) {
+			continue
+		}
+		prevRecords := prevRecordMap[rt.Name]
+		oldSet, newSet := normalizeRecords(prevRecords), normalizeRecords(results[rt.Name])
+
+		add := func(action string, value string, oldValue string, newValue string) {
+			change := models.GfnCollectorDNSChange{
+				ID:         util.GenerateId(),
+				Name:       name,
+				Type:       rt.Name,
+				Action:     action,
+				Value:      value,
+				CreateTime: now,
+			}
+			if oldValue != "" {
+				change.OldValue = &oldValue
+			}
+			if newValue != "" {
+				change.NewValue = &newValue
+			}
+			changes = append(changes, change)
+		}
+
+		for _, value := range sortedKeys(newSet) {
+			current := newSet[value]
+			old, ok := oldSet[value]
+			if !ok {
+				add(models.DNSChangeAdded, value, "", value)
+				continue
+			}
+			// 只比较权威 TTL, 两边都有时才可信
+			if old.AuthTTL != 0 && current.AuthTTL != 0 && old.AuthTTL != current.AuthTTL {
+				add(models.DNSChangeTTL, value, strconv.Itoa(int(old.AuthTTL)), strconv.Itoa(int(current.AuthTTL)))
+			}
+			if old.ProviderType != "" && current.ProviderType != "" && providerLabel(old) != providerLabel(current) {
+				add(models.DNSChangeProvider, value, providerLabel(old), providerLabel(current))
+			}
+			if old.ASN != "" && current.ASN != "" && old.ASN != current.ASN {
+				add(models.DNSChangeASN, value, old.ASN, current.ASN)
+			}
+		}
+		for _, value := range sortedKeys(oldSet) {
+			if _, ok := newSet[value]; !ok {
+				add(models.DNSChangeRemoved, value, value, "")
+			}
+		}
+	}
+	return changes
+}
+
+// 按记录值去重, 域名类的值统一小写
+func normalizeRecords(records []models.DNSRecord) map[string]models.DNSRecord {
+	res := make(map[string]models.DNSRecord)
+	for _, rec := range records {
+		value := strings.TrimSpace(rec.Value)
+		if net.ParseIP(value) == nil {
+			value = strings.ToLower(value)
+		}
+		res[value] = rec
+	}
+	return res
+}
+
+// 服务商和分类, 如 CDN Cloudflare; 未识别时只有分类
+func providerLabel(rec models.DNSRecord) string {
+	return strings.TrimSpace(rec.ProviderType + " " + rec.Provider)
+}
+
+// 采集记录中的全部记录, 旧数据没有 records 列时从各类型的列读取
+func snapshotRecords(row *models.GfnCollectorLogDn) map[string][]models.DNSRecord {
+	res := make(map[string][]models.DNSRecord)
+	if row.Records != nil {
+		_ = json.Unmarshal([]byte(*row.Records), &res)
+		return res
+	}
+	columns := map[string]*string{
+		"A":     row.A,
+		"AAAA":  row.Aaaa,
+		"CNAME": row.Cname,
+		"MX":    row.Mx,
+		"NS":    row.Ns,
+		"TXT":   row.Txt,
+		"SOA":   row.Soa,
+		"CAA":   row.Caa,
+	}
+	for k, column := range columns {
+		if column == nil {
+			continue
+		}
+		var records []models.DNSRecord
+		if json.Unmarshal([]byte(*column), &records) == nil {
+			res[k] = records
+		}
+	}
+	return res
+}
+
+// 采集记录中各记录类型的应答, 旧数据没有 responses 列时返回 false
+func snapshotResponses(row *models.GfnCollectorLogDn) (map[string]models.DNSResponse, bool) {
+	if row.Responses == nil {
+		return nil, false
+	}
+	res := make(map[string]models.DNSResponse)
+	if json.Unmarshal([]byte(*row.Responses), &res) != nil {
+		return nil, false
+	}
+	return res, true
+}
+
+// 按字典序返回 map 的 key, 保证变更记录顺序稳定
+func sortedKeys(m map[string]models.DNSRecord) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/dns/models"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"github.com/miekg/dns"
+)
+
+// ============== DNS解析 - 委派健康检查 ==============
+
+// 从上级区域获取委派的 NS, 直接查询每个权威服务器, 检查序列号、应答和 NS 是否一致
+func checkDelegation(domain string) models.DelegationResult {
+	res := models.DelegationResult{
+		ParentNS:       []string{},
+		ChildNS:        []string{},
+		Servers:        []models.NameserverCheck{},
+		MissingInChild: []string{},
+		ExtraInChild:   []string{},
+		Issues:         []string{},
+	}
+
+	zone, childNS := findZoneNameservers(domain)
+	if zone == "" {
+		res.Issues = append(res.Issues, "zone not found")
+		return res
+	}
+	res.Zone = zone
+	labels := dns.SplitDomainName(zone)
+	res.Parent = dns.Fqdn(strings.Join(labels[1:], "."))
+
+	// 上级区域的委派
+	parentNS, glue, err := queryParentDelegation(zone, res.Parent)
+	if err != "" {
+		res.Issues = append(res.Issues, err)
+	}
+	res.ParentNS = parentNS
+
+	// 合并两边的 NS, 逐个检查
+	names := make(map[string]struct{})
+	for _, ns := range append(append([]string{}, parentNS...), childNS...) {
+		names[strings.ToLower(ns)] = struct{}{}
+	}
+	var checkMu sync.Mutex
+	var checkWG sync.WaitGroup
+	for ns := range names {
+		ips := filterAddresses(glue[ns])
+		if len(ips) == 0 {
+			ips = filterAddresses(resolveHostAll(ns))
+		}
+		if len(ips) == 0 {
+			checkMu.Lock()
+			res.Servers = append(res.Servers, models.NameserverCheck{Name: ns, Reason: "nameserver has no address", NS: []string{}, Answers: []string{}})
+			checkMu.Unlock()
+			continue
+		}
+		for _, ip := range ips {
+			checkWG.Add(1)
+			go func(ns string, ip string) {
+				defer checkWG.Done()
+				check := checkNameserver(zone, domain, ns, ip)
+				checkMu.Lock()
+				res.Servers = append(res.Servers, check)
+				checkMu.Unlock()
+			}(ns, ip)
+		}
+	}
+	checkWG.Wait()
+	sort.Slice(res.Servers, func(i, j int) bool {
+		if res.Servers[i].Name != res.Servers[j].Name {
+			return res.Servers[i].Name < res.Servers[j].Name
+		}
+		return res.Servers[i].IP < res.Servers[j].IP
+	})
+
+	analyzeDelegation(&res)
+	return res
+}
+
+// 向上级区域的权威服务器查询 NS, 返回委派的 NS 和胶水记录
+func queryParentDelegation(zone string, parent string) ([]string, map[string][]string, string) {
+	glue := make(map[string][]string)
+	parentServers := lookupNS(parent)
+	if len(parentServers) == 0 {
+		return []string{}, glue, "parent nameservers not found for " + parent
+	}
+
+	lastErr := ""
+	for _, server := range parentServers {
+		ip := resolveHost(server)
+		if ip == "" {
+			continue
+		}
+		r, _ := parseResolver(env.ResolverConfig{Name: strings.TrimSuffix(server, "."), Address: net.JoinHostPort(ip, "53")})
+		m := new(dns.Msg)
+		m.SetQuestion(zone, dns.TypeNS)
+		m.RecursionDesired = false
+		in, _, err := exchange(m, r)
+		if err != nil {
+			lastErr = "parent " + server + " unreachable: " + err.Error()
+			continue
+		}
+
+		// 委派在 Authority 段, 上级同时也是权威时 (同一服务商托管) 可能在 Answer 段
+		var nameservers []string
+		for _, rr := range append(append([]dns.RR{}, in.Ns...), in.Answer...) {
+			if ns, ok := rr.(*dns.NS); ok && strings.EqualFold(ns.Hdr.Name, zone) {
+				nameservers = append(nameservers, strings.ToLower(ns.Ns))
+			}
+		}
+		for _, rr := range in.Extra {
+			switch v := rr.(type) {
+			case *dns.A:
+				glue[strings.ToLower(v.Hdr.Name)] = append(glue[strings.ToLower(v.Hdr.Name)], v.A.String())
+			case *dns.AAAA:
+				glue[strings.ToLower(v.Hdr.Name)] = append(glue[strings.ToLower(v.Hdr.Name)], v.AAAA.String())
+			}
+		}
+		if len(nameservers) > 0 {
+			sort.Strings(nameservers)
+			return uniqueStrings(nameservers), glue, ""
+		}
+		lastErr = fmt.Sprintf("parent %s returned no delegation (%s)", server, dns.RcodeToString[in.Rcode])
+	}
+	return []string{}, glue, lastErr
+}
+
+// 直接查询单个权威服务器
+func checkNameserver(zone string, domain string, name string, ip string) models.NameserverCheck {
+	check := models.NameserverCheck{Name: name, IP: ip, NS: []string{}, Answers: []string{}}
+	r, _ := parseResolver(env.ResolverConfig{Name: strings.TrimSuffix(name, "."), Address: net.JoinHostPort(ip, "53")})
+
+	query := func(qname string, qtype uint16) (*dns.Msg, error) {
+		m := new(dns.Msg)
+		m.SetQuestion(qname, qtype)
+		m.RecursionDesired = false
+		in, rtt, err := exchange(m, r)
+		if qtype == dns.TypeSOA {
+			check.RTT = rtt
+		}
+		return in, err
+	}
+
+	// SOA 判断可达性和是否为权威
+	in, err := query(zone, dns.TypeSOA)
+	if err != nil {
+		check.Reason = "unreachable: " + err.Error()
+		return check
+	}
+	check.Reachable = true
+	if in.Rcode != dns.RcodeSuccess {
+		check.Lame, check.Reason = true, "lame: rcode "+dns.RcodeToString[in.Rcode]
+		return check
+	}
+	if !in.Authoritative {
+		check.Lame, check.Reason = true, "lame: answer not authoritative"
+		return check
+	}
+	for _, rr := range in.Answer {
+		if soa, ok := rr.(*dns.SOA); ok {
+			check.Serial = soa.Serial
+		}
+	}
+
+	// 区域自身的 NS
+	if in, err = query(zone, dns.TypeNS); err == nil {
+		for _, rr := range in.Answer {
+			if ns, ok := rr.(*dns.NS); ok {
+				check.NS = append(check.NS, strings.ToLower(ns.Ns))
+			}
+		}
+		sort.Strings(check.NS)
+	}
+
+	// 目标域名的 A / AAAA
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		if in, err = query(dns.Fqdn(domain), qtype); err != nil {
+			continue
+		}
+		for _, rr := range in.Answer {
+			switch v := rr.(type) {
+			case *dns.A:
+				check.Answers = append(check.Answers, v.A.String())
+			case *dns.AAAA:
+				check.Answers = append(check.Answers, v.AAAA.String())
+			case *dns.CNAME:
+				check.Answers = append(check.Answers, "CNAME "+strings.ToLower(v.Target))
+			}
+		}
+	}
+	sort.Strings(check.Answers)
+	check.Answers = uniqueStrings(check.Answers)
+	return check
+}
+
+// 汇总各服务器的检查结果
+func analyzeDelegation(res *models.DelegationResult) {
+	serials := make(map[uint32]struct{})
+	answers := make(map[string]struct{})
+	childNS := make(map[string]struct{})
+	for _, check := range res.Servers {
+		switch {
+		case !check.Reachable:
+			res.Issues = append(res.Issues, fmt.Sprintf("%s (%s) %s", check.Name, check.IP, check.Reason))
+			continue
+		case check.Lame:
+			res.Issues = append(res.Issues, fmt.Sprintf("%s (%s) %s", check.Name, check.IP, check.Reason))
+			continue
+		}
+		serials[check.Serial] = struct{}{}
+		answers[strings.Join(check.Answers, ",")] = struct{}{}
+		for _, ns := range check.NS {
+			childNS[ns] = struct{}{}
+		}
+	}
+
+	res.SerialConsistent = len(serials) <= 1
+	if !res.SerialConsistent {
+		res.Issues = append(res.Issues, fmt.Sprintf("SOA serial mismatch across %d values", len(serials)))
+	}
+	res.AnswersConsistent = len(answers) <= 1
+	if !res.AnswersConsistent {
+		res.Issues = append(res.Issues, "authoritative answers differ between nameservers")
+	}
+
+	for ns := range childNS {
+		res.ChildNS = append(res.ChildNS, ns)
+	}
+	sort.Strings(res.ChildNS)
+	parent := make(map[string]struct{})
+	for _, ns := range res.ParentNS {
+		parent[ns] = struct{}{}
+		if _, ok := childNS[ns]; !ok && len(childNS) > 0 {
+			res.MissingInChild = append(res.MissingInChild, ns)
+		}
+	}
+	if len(parent) > 0 {
+		for _, ns := range res.ChildNS {
+			if _, ok := parent[ns]; !ok {
+				res.ExtraInChild = append(res.ExtraInChild, ns)
+			}
+		}
+	}
+	res.NSConsistent = len(res.MissingInChild) == 0 && len(res.ExtraInChild) == 0
+	if !res.NSConsistent {
+		res.Issues = append(res.Issues, fmt.Sprintf("parent/child NS mismatch: missing in child %v, extra in child %v", res.MissingInChild, res.ExtraInChild))
+	}
+	res.Healthy = len(res.Issues) == 0
+}
+
+// 解析主机名的全部 IPv4 / IPv6 地址
+func resolveHostAll(host string) []string {
+	var ips []string
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		m := new(dns.Msg)
+		m.SetQuestion(dns.Fqdn(host), qtype)
+		in, _, err := exchange(m, resolver)
+		if err != nil {
+			continue
+		}
+		for _, rr := range in.Answer {
+			switch v := rr.(type) {
+			case *dns.A:
+				ips = append(ips, v.A.String())
+			case *dns.AAAA:
+				ips = append(ips, v.AAAA.String())
+			}
+		}
+	}
+	return ips
+}
+
+// 未开启 IPv6 检查时只保留 IPv4 地址, 避免在纯 IPv4 网络中把所有服务器判为不可达
+func filterAddresses(ips []string) []string {
+	if env.GetServerConfig().Collector.Dns.CheckIPv6 {
+		return ips
+	}
+	var res []string
+	for _, ip := range ips {
+		if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() != nil {
+			res = append(res, ip)
+		}
+	}
+	return res
+}
+
+// 去掉已排序切片中的重复项
+func uniqueStrings(values []string) []string {
+	res := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			res = append(res, v)
+		}
+	}
+	return res
+}
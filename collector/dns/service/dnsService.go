@@ -1,9 +1,11 @@
 package service
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -12,12 +14,12 @@ import (
 	"github.com/GoFurry/gofurry-nav-collector/collector/dns/models"
 	"github.com/GoFurry/gofurry-nav-collector/common"
 	"github.com/GoFurry/gofurry-nav-collector/common/log"
+	cm "github.com/GoFurry/gofurry-nav-collector/common/models"
 	cs "github.com/GoFurry/gofurry-nav-collector/common/service"
 	"github.com/GoFurry/gofurry-nav-collector/common/util"
 	"github.com/GoFurry/gofurry-nav-collector/roof/env"
 	"github.com/miekg/dns"
 	_ "github.com/miekg/dns"
-	"github.com/oschwald/geoip2-golang"
 	"github.com/sourcegraph/conc/pool"
 )
 
@@ -26,6 +28,8 @@ const (
 	MaxDepth = 2
 	// PTR 查询并发数控制
 	PTRWorkers = 5
+	// PTR 最短缓存时间, 避免 TTL 为 0 的记录每次都重新查询
+	minPTRCacheTTL = time.Minute
 )
 
 // 按域名并行查 加锁
@@ -34,11 +38,15 @@ var dnsRWLock sync.RWMutex
 var wg sync.WaitGroup
 
 // 缓存 IP
-var geoCache sync.Map                        // geoCache 缓存 IP 的 GeoIP/ASN 查询结果
-var ptrCache sync.Map                        // ptrCache 缓存 IP 的反向 PTR 查询结果
-var ptrSem = make(chan struct{}, PTRWorkers) // ptrSem 用于限制 PTR 查询并发
+var geoCache = initGeoCache()                                                                        // geoCache 缓存 IP 的 GeoIP/ASN 查询结果
+var ptrCache = util.NewTTLCache[string, string](env.GetServerConfig().Collector.Dns.PtrCacheSize, 0) // ptrCache 缓存 IP 的反向 PTR 查询结果, 按记录 TTL 过期
+var ptrSem = make(chan struct{}, PTRWorkers)                                                         // ptrSem 用于限制 PTR 查询并发
 
-var resolver = env.GetServerConfig().Collector.Dns.Resolver
+// 默认解析器
+var resolver = initResolver()
+
+// 采集的记录类型
+var recordTypes = initRecordTypes()
 
 // ============== DNS解析 - 初始化部分 ==============
 
@@ -59,6 +67,33 @@ func InitDNSOnStart() {
 	fmt.Println("DNS 模块初始化结束...")
 }
 
+// GeoIP 缓存, 未配置缓存时间时默认 24 小时
+func initGeoCache() *util.TTLCache[string, cm.GeoInfo] {
+	ttl := env.GetServerConfig().Collector.Dns.GeoCacheTTL
+	if ttl <= 0 {
+		ttl = 24
+	}
+	return util.NewTTLCache[string, cm.GeoInfo](env.GetServerConfig().Collector.Dns.GeoCacheSize, time.Duration(ttl)*time.Hour)
+}
+
+// 读取配置的记录类型, 未配置或全部无效时使用默认列表
+func initRecordTypes() []models.RecordType {
+	var res []models.RecordType
+	for _, name := range env.GetServerConfig().Collector.Dns.RecordTypes {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		qtype, ok := dns.StringToType[name]
+		if !ok {
+			log.Error("不支持的记录类型: ", name)
+			continue
+		}
+		res = append(res, models.RecordType{Type: qtype, Name: name})
+	}
+	if len(res) == 0 {
+		return models.RecordTypes
+	}
+	return res
+}
+
 // ============== DNS解析 - 执行部分 ==============
 
 // 执行 ParseDNS
@@ -80,6 +115,15 @@ func ParseDNS() {
 		return
 	}
 
+	// 加载服务商数据 (文件有更新时重新加载)
+	if ruleErr := cs.LoadProviderRules(); ruleErr != nil {
+		log.Error(ruleErr.GetMsg())
+	}
+	// 加载保留地址段数据 (文件有更新时重新加载)
+	if bogonErr := loadBogonRanges(); bogonErr != nil {
+		log.Error(bogonErr.GetMsg())
+	}
+
 	log.Info("DNS 采集开始")
 	// 遍历站点列表, 每个站点开一个线程执行采集
 	for _, v := range requestList {
@@ -90,6 +134,11 @@ func ParseDNS() {
 	wg.Wait()
 	log.Info("DNS 采集结束")
 
+	geoStats, ptrStats := geoCache.Stats(), ptrCache.Stats()
+	log.Info(fmt.Sprintf("DNS 缓存统计 GeoIP: %d/%d 命中 %d 未命中 %d 淘汰 %d, PTR: %d/%d 命中 %d 未命中 %d 淘汰 %d",
+		geoStats.Size, geoStats.Capacity, geoStats.Hits, geoStats.Misses, geoStats.Evictions,
+		ptrStats.Size, ptrStats.Capacity, ptrStats.Hits, ptrStats.Misses, ptrStats.Evictions))
+
 	// 每个域名仅保留 500 条 DNS 记录
 	count, deleteErr := dao.GetDNSDao().DeleteByNum(env.GetServerConfig().Collector.Dns.LogCount)
 	if deleteErr != nil {
@@ -108,26 +157,8 @@ func getDNSResult(site models.GfnCollectorDomain) func() {
 		}()
 		defer wg.Done() // 确保线程结束时数组减少
 
-		// 打开 GeoIP / ASN 数据库
-		dbPath := env.GetServerConfig().Collector.Dns.Geolite2Path
-		countryDB, err := geoip2.Open(dbPath + "GeoLite2-Country.mmdb")
-		if err != nil {
-			log.Error("打开 Country DB 失败: ", err.Error())
-		}
-		defer countryDB.Close()
-		cityDB, err := geoip2.Open(dbPath + "GeoLite2-City.mmdb")
-		if err != nil {
-			log.Error("打开 City DB 失败: ", err.Error())
-		}
-		defer cityDB.Close()
-		asnDB, err := geoip2.Open(dbPath + "GeoLite2-ASN.mmdb")
-		if err != nil {
-			log.Error("打开 ASN DB 失败: ", err.Error())
-		}
-		defer asnDB.Close()
-
 		// 执行 Request 获取结果
-		results := performDNSQuery(site, asnDB, cityDB, countryDB)
+		results, responses, queryStats := performDNSQuery(site)
 
 		var siteName string
 		if site.Prefix != nil {
@@ -136,6 +167,84 @@ func getDNSResult(site models.GfnCollectorDomain) func() {
 			siteName = site.Name
 		}
 
+		// 权威 TTL, 用于变更对比
+		fillAuthTTL(siteName, results)
+
+		// DNSSEC 验证, 只有验证通过的记录类型标记 DNSSEC
+		dnssecResult := validateDNSSEC(siteName)
+		markDNSSEC(results, dnssecResult)
+		dnssecJson, _ := json.Marshal(dnssecResult)
+		dnssecRecord := string(dnssecJson)
+
+		// 多解析器对比
+		comparison := compareResolvers(siteName)
+		comparisonJson, _ := json.Marshal(comparison)
+		comparisonRecord := string(comparisonJson)
+
+		// 委派健康检查
+		delegation := checkDelegation(siteName)
+		delegationJson, _ := json.Marshal(delegation)
+		delegationRecord := string(delegationJson)
+
+		// 邮件安全, 按主域名检查
+		var mailRecord *string
+		if env.GetServerConfig().Collector.Dns.MailCheck {
+			mail := checkMailSecurity(site.Name)
+			mailJson, _ := json.Marshal(mail)
+			mailValue := string(mailJson)
+			mailRecord = &mailValue
+			// 按主域名写入发现表, 查询失败的检查项保留上次的结果
+			saveGroupedFindings(site.Name, models.MailChecks, mail.Skipped, mail.Findings, models.SeverityLow)
+		}
+
+		// 子域名接管, 高危发现写入发现表
+		var takeoverRecord *string
+		if env.GetServerConfig().Collector.Dns.TakeoverCheck {
+			takeover, complete := checkTakeover(results, responses)
+			takeoverJson, _ := json.Marshal(takeover)
+			takeoverValue := string(takeoverJson)
+			takeoverRecord = &takeoverValue
+			// 有查询失败时结果不完整, 保留已有发现
+			if complete {
+				saveFindings(siteName, models.FindingCheckTakeover, takeover, models.SeverityHigh)
+			}
+		}
+
+		// 安全审计, 发现写入发现表
+		var auditRecord *string
+		if env.GetServerConfig().Collector.Dns.AuditCheck {
+			audit := auditDNS(siteName, delegation)
+			auditJson, _ := json.Marshal(audit)
+			auditValue := string(auditJson)
+			auditRecord = &auditValue
+			saveAuditFindings(siteName, audit)
+		}
+
+		// IP 黑名单, 列入时写入发现表
+		var dnsblRecord *string
+		if dnsbl := checkDNSBL(results); dnsbl != nil {
+			dnsblJson, _ := json.Marshal(dnsbl)
+			dnsblValue := string(dnsblJson)
+			dnsblRecord = &dnsblValue
+			// 地址查询或黑名单查询失败时结果不完整, 保留已有发现
+			if dnsblComplete(dnsbl) && !anyTypeFailed(responses, dns.TypeA, dns.TypeAAAA, dns.TypeMX) {
+				saveFindings(siteName, models.FindingCheckDNSBL, dnsblFindings(dnsbl), models.SeverityMedium)
+			}
+		}
+
+		// ECS, 各地区客户端拿到的应答
+		var ecsRecord *string
+		if ecs := checkECS(siteName); ecs != nil {
+			ecsJson, _ := json.Marshal(ecs)
+			ecsValue := string(ecsJson)
+			ecsRecord = &ecsValue
+		}
+
+		// 查询统计
+		buildResolverStats(&queryStats, responses, comparison)
+		statsJson, _ := json.Marshal(queryStats)
+		statsRecord := string(statsJson)
+
 		// Ping 结果储存回 redis
 		resultKey := "dns:" + siteName
 		resultMap := make(map[string]string)
@@ -143,14 +252,51 @@ func getDNSResult(site models.GfnCollectorDomain) func() {
 			jsonResult, _ := json.Marshal(result)
 			resultMap[k] = string(jsonResult)
 		}
+		resultMap["RESOLVERS"] = comparisonRecord
+		responsesJson, _ := json.Marshal(responses)
+		responsesRecord := string(responsesJson)
+		resultMap["RESPONSES"] = responsesRecord
+		resultMap["DNSSEC"] = dnssecRecord
+		resultMap["STATS"] = statsRecord
+		resultMap["DELEGATION"] = delegationRecord
+		if mailRecord != nil {
+			resultMap["MAIL"] = *mailRecord
+		}
+		if takeoverRecord != nil {
+			resultMap["TAKEOVER"] = *takeoverRecord
+		}
+		if auditRecord != nil {
+			resultMap["AUDIT"] = *auditRecord
+		}
+		if dnsblRecord != nil {
+			resultMap["DNSBL"] = *dnsblRecord
+		}
+		if ecsRecord != nil {
+			resultMap["ECS"] = *ecsRecord
+		}
 		gfError := cs.HSetMap(resultKey, resultMap)
 		if gfError != nil {
-			log.Error("存储request结果失败: ", err.Error())
+			log.Error("存储request结果失败: ", gfError.GetMsg())
 		}
 
+		// 全部记录按类型存一列, 新增记录类型不需要改表
+		recordsJson, _ := json.Marshal(results)
+		recordsRecord := string(recordsJson)
+
 		newRecord := models.GfnCollectorLogDn{
 			ID:         util.GenerateId(),
 			Name:       siteName,
+			Records:    &recordsRecord,
+			Resolvers:  &comparisonRecord,
+			Responses:  &responsesRecord,
+			Dnssec:     &dnssecRecord,
+			Stats:      &statsRecord,
+			Delegation: &delegationRecord,
+			Mail:       mailRecord,
+			Ecs:        ecsRecord,
+			Dnsbl:      dnsblRecord,
+			Takeover:   takeoverRecord,
+			Audit:      auditRecord,
 			CreateTime: time.Now(),
 		}
 		for k, v := range results {
@@ -160,7 +306,7 @@ func getDNSResult(site models.GfnCollectorDomain) func() {
 			}
 			jsonRecord := string(marshal)
 			if &jsonRecord != nil {
-				newRecord.Status = "success"
+				newRecord.Status = models.DNSStatusSuccess
 			}
 			switch k {
 			case "A":
@@ -182,20 +328,42 @@ func getDNSResult(site models.GfnCollectorDomain) func() {
 			default:
 			}
 		}
-		if newRecord.Status != "success" {
-			newRecord.Status = "failure"
+		if newRecord.Status != models.DNSStatusSuccess {
+			newRecord.Status = models.DNSStatusFailure
+		}
+		// 域名不存在单独标记
+		if responses["SOA"].Rcode == dns.RcodeToString[dns.RcodeNameError] || responses["A"].Rcode == dns.RcodeToString[dns.RcodeNameError] {
+			newRecord.Status = models.DNSStatusNXDomain
+		}
+
+		// 与上一次快照对比
+		prev, daoErr := dao.GetDNSDao().GetLatest(siteName)
+		if daoErr != nil {
+			log.Error("获取上一次DNS采集结果失败: ", daoErr.GetMsg())
+		}
+		changes := diffDNSSnapshot(siteName, prev, results, responses)
+		if daoErr = dao.GetDNSDao().AddChanges(changes); daoErr != nil {
+			log.Error("添加DNS变更记录失败: ", daoErr.GetMsg())
+		}
+		if len(changes) > 0 {
+			log.Info(siteName, " DNS 记录变更 ", len(changes), " 条")
+		}
+
+		// 记录未变化时可跳过写入
+		if env.GetServerConfig().Collector.Dns.SkipUnchanged && prev != nil && len(changes) == 0 && prev.Status == newRecord.Status {
+			return
 		}
 
 		// 存数据库
-		daoErr := dao.GetDNSDao().Add(&newRecord)
+		daoErr = dao.GetDNSDao().Add(&newRecord)
 		if daoErr != nil {
-			log.Error("添加DNS采集结果到数据库失败: ", err.Error())
+			log.Error("添加DNS采集结果到数据库失败: ", daoErr.GetMsg())
 		}
 
 	}
 }
 
-func performDNSQuery(site models.GfnCollectorDomain, asnDB *geoip2.Reader, cityDB *geoip2.Reader, countryDB *geoip2.Reader) map[string][]models.DNSRecord {
+func performDNSQuery(site models.GfnCollectorDomain) (map[string][]models.DNSRecord, map[string]models.DNSResponse, models.DNSQueryStats) {
 	// 按记录类型并行查 加锁
 	var queryMu sync.Mutex
 	var queryMG sync.WaitGroup
@@ -205,8 +373,11 @@ func performDNSQuery(site models.GfnCollectorDomain, asnDB *geoip2.Reader, cityD
 	var globalTTLsum uint64
 	var globalDurations []time.Duration
 	var globalTotalTime time.Duration
+	var globalRecords int
 	// 最终结果
 	result := make(map[string][]models.DNSRecord)
+	responses := make(map[string]models.DNSResponse)
+	typeStats := make(map[string]models.DNSStatistics)
 
 	var domain string
 	if site.Prefix != nil {
@@ -215,15 +386,36 @@ func performDNSQuery(site models.GfnCollectorDomain, asnDB *geoip2.Reader, cityD
 		domain = site.Name
 	}
 
+	// 查询任务, SRV 按服务名分别查询
+	type queryJob struct {
+		key   string // 应答和统计的 key
+		qname string
+		rt    models.RecordType
+	}
+	var jobs []queryJob
+	for _, rt := range recordTypes {
+		if rt.Type != dns.TypeSRV {
+			jobs = append(jobs, queryJob{key: rt.Name, qname: domain, rt: rt})
+			continue
+		}
+		for _, srv := range env.GetServerConfig().Collector.Dns.SrvNames {
+			jobs = append(jobs, queryJob{key: rt.Name + " " + srv, qname: srv + "." + domain, rt: rt})
+		}
+	}
+
 	// 并行查询每种记录类型
-	for _, rt := range models.RecordTypes {
+	for _, job := range jobs {
 		queryMG.Add(1)
-		go func(rt models.RecordType) {
+		go func(job queryJob) {
 			defer queryMG.Done()
 
-			records, stats, err := queryDNS(domain, rt.Type, resolver, countryDB, cityDB, asnDB, 0)
+			records, stats, response, err := queryDNS(job.qname, job.rt.Type, resolver, 0)
+			queryMu.Lock()
+			responses[job.key] = response
+			typeStats[job.key] = stats
+			queryMu.Unlock()
 			if err != nil {
-				log.Error(domain+" 查询 ", rt.Name, " 失败: ", err.GetMsg())
+				log.Error(domain+" 查询 ", job.key, " 失败: ", err.GetMsg())
 				return
 			}
 
@@ -233,67 +425,140 @@ func performDNSQuery(site models.GfnCollectorDomain, asnDB *geoip2.Reader, cityD
 				result[record.Type] = append(result[record.Type], record)
 			}
 
-			if stats.MinTTL < globalMinTTL {
-				globalMinTTL = stats.MinTTL
-			}
-			if stats.MaxTTL > globalMaxTTL {
-				globalMaxTTL = stats.MaxTTL
+			if len(records) > 0 {
+				if stats.MinTTL < globalMinTTL {
+					globalMinTTL = stats.MinTTL
+				}
+				if stats.MaxTTL > globalMaxTTL {
+					globalMaxTTL = stats.MaxTTL
+				}
+				globalDurations = append(globalDurations, stats.MinTime, stats.MaxTime)
 			}
 			globalTTLsum += uint64(stats.AvgTTL * float64(len(records)))
-			globalDurations = append(globalDurations, stats.MinTime, stats.MaxTime)
+			globalRecords += len(records)
 			globalTotalTime += stats.TotalTime
 			queryMu.Unlock()
-		}(rt)
+		}(job)
 
 	}
 	queryMG.Wait()
-	return result
+
+	// 汇总统计
+	total := models.DNSStatistics{
+		MaxTTL:    globalMaxTTL,
+		TotalTime: globalTotalTime,
+		Records:   globalRecords,
+	}
+	if globalRecords > 0 {
+		total.MinTTL = globalMinTTL
+		total.AvgTTL = float64(globalTTLsum) / float64(globalRecords)
+		total.MinTime, total.MaxTime = globalDurations[0], globalDurations[0]
+		var sum time.Duration
+		for _, d := range globalDurations {
+			sum += d
+			if d < total.MinTime {
+				total.MinTime = d
+			}
+			if d > total.MaxTime {
+				total.MaxTime = d
+			}
+		}
+		total.AvgTime = sum / time.Duration(len(globalDurations))
+	}
+	var rttSum time.Duration
+	for _, stats := range typeStats {
+		rttSum += stats.RTT
+	}
+	if len(typeStats) > 0 {
+		total.RTT = rttSum / time.Duration(len(typeStats))
+	}
+
+	queryStats := models.DNSQueryStats{Total: total, Types: typeStats, Resolvers: make(map[string]models.ResolverStats)}
+	return result, responses, queryStats
+}
+
+// 按解析器汇总耗时, 包括默认解析器和多解析器对比的查询
+func buildResolverStats(stats *models.DNSQueryStats, responses map[string]models.DNSResponse, comparison models.ResolverComparison) {
+	add := func(name string, transport string, rtt time.Duration, failed bool) {
+		s := stats.Resolvers[name]
+		s.Transport = transport
+		s.Queries++
+		if failed {
+			s.Failures++
+		} else {
+			if s.Queries-s.Failures == 1 || rtt < s.MinRTT {
+				s.MinRTT = rtt
+			}
+			if rtt > s.MaxRTT {
+				s.MaxRTT = rtt
+			}
+			// 增量平均
+			n := time.Duration(s.Queries - s.Failures)
+			s.AvgRTT += (rtt - s.AvgRTT) / n
+		}
+		stats.Resolvers[name] = s
+	}
+	for k, response := range responses {
+		add(resolver.Name, resolver.Transport, stats.Types[k].RTT, response.Error != "")
+	}
+	for _, answer := range comparison.Answers {
+		add(answer.Resolver, answer.Transport, answer.RTT, answer.Error != "")
+	}
 }
 
 // ============== DNS解析 - 采集和解析部分 ==============
 
-func queryDNS(domain string, qtype uint16, resolver string, asnDB *geoip2.Reader, cityDB *geoip2.Reader, countryDB *geoip2.Reader, depth int) ([]models.DNSRecord, models.DNSStatistics, common.GFError) {
+func queryDNS(domain string, qtype uint16, resolver *models.Resolver, depth int) ([]models.DNSRecord, models.DNSStatistics, models.DNSResponse, common.GFError) {
+	response := models.DNSResponse{Type: dns.TypeToString[qtype]}
 	// 防止递归过深
 	if depth > MaxDepth {
-		return nil, models.DNSStatistics{}, nil
+		return nil, models.DNSStatistics{}, response, nil
 	}
 
 	start := time.Now()
 
-	// UDP DNS 查询客户端
-	c := &dns.Client{Net: "udp"}
 	m := new(dns.Msg)
 	m.SetQuestion(dns.Fqdn(domain), qtype)
 	m.SetEdns0(4096, true) // 支持 DNSSEC
+	// 请求解析器返回 NSID
+	m.IsEdns0().Option = append(m.IsEdns0().Option, &dns.EDNS0_NSID{Code: dns.EDNS0NSID})
 
-	// 执行 DNS 查询
-	in, _, err := c.Exchange(m, resolver)
+	// 执行 DNS 查询, 传输方式由解析器地址决定, UDP 截断时改用 TCP
+	in, rtt, tcpFallback, err := exchangeWithFallback(m, resolver)
 	if err != nil {
-		return nil, models.DNSStatistics{}, common.NewServiceError("DNS 查询失败: " + err.Error())
+		response.Error = err.Error()
+		return nil, models.DNSStatistics{}, response, common.NewServiceError("DNS 查询失败: " + err.Error())
 	}
 	totalTime := time.Since(start)
-
-	// 检查是否有 DNSSEC
-	dnssec := false
-	for _, rr := range in.Answer {
-		if rr.Header().Rrtype == dns.TypeRRSIG {
-			dnssec = true
-			break
-		}
-	}
+	response = buildDNSResponse(response.Type, in, tcpFallback)
 
 	var results []models.DNSRecord
 	var ttlSum uint32
 	minTTL, maxTTL := uint32(1<<32-1), uint32(0)
 	var durations []time.Duration
 
+	// 解析链上的域名, 用于按 CNAME 后缀识别服务商
+	chain := []string{domain}
+	for _, rr := range in.Answer {
+		if v, ok := rr.(*dns.CNAME); ok {
+			chain = append(chain, v.Target)
+		}
+	}
+
 	// 遍历每条 Answer 记录
 	for _, rr := range in.Answer {
+		// 签名由 DNSSEC 验证单独处理
+		if rr.Header().Rrtype == dns.TypeRRSIG {
+			continue
+		}
 		recStart := time.Now()
 		rec := models.DNSRecord{
-			Type:   dns.TypeToString[rr.Header().Rrtype],
-			TTL:    rr.Header().Ttl,
-			DNSSEC: dnssec,
+			Type:      dns.TypeToString[rr.Header().Rrtype],
+			Name:      rr.Header().Name,
+			TTL:       rr.Header().Ttl,
+			Resolver:  resolver.Name,
+			Transport: resolver.Transport,
+			RTT:       rtt,
 		}
 		ttlSum += rr.Header().Ttl
 		if rr.Header().Ttl < minTTL {
@@ -307,33 +572,35 @@ func queryDNS(domain string, qtype uint16, resolver string, asnDB *geoip2.Reader
 		switch v := rr.(type) {
 		case *dns.A:
 			rec.Value = v.A.String()
-			rec.Country, rec.City, rec.ASN, rec.ISP = lookupGeoASN(v.A, countryDB, cityDB, asnDB)
-			rec.ProviderType = detectCDN(rec.ASN, v.A, domain)
+			geo := lookupGeoASN(v.A)
+			rec.Country, rec.City, rec.ASN, rec.ISP = geo.Country, geo.City, geo.ASN, geo.ISP
+			provider := cs.DetectProvider(v.A, geo.ASNumber, chain)
+			rec.ProviderType, rec.Provider, rec.ProviderHit = provider.Category, provider.Name, provider.Evidence
 			rec.ReversePTR = reversePTR(v.A)
-			rec.Hijacked = detectHijack(v.A, in, v.Hdr.Ttl)
 		case *dns.AAAA:
 			rec.Value = v.AAAA.String()
-			rec.Country, rec.City, rec.ASN, rec.ISP = lookupGeoASN(v.AAAA, countryDB, cityDB, asnDB)
-			rec.ProviderType = detectCDN(rec.ASN, v.AAAA, domain)
+			geo := lookupGeoASN(v.AAAA)
+			rec.Country, rec.City, rec.ASN, rec.ISP = geo.Country, geo.City, geo.ASN, geo.ISP
+			provider := cs.DetectProvider(v.AAAA, geo.ASNumber, chain)
+			rec.ProviderType, rec.Provider, rec.ProviderHit = provider.Category, provider.Name, provider.Evidence
 			rec.ReversePTR = reversePTR(v.AAAA)
-			rec.Hijacked = detectHijack(v.AAAA, in, v.Hdr.Ttl)
 		case *dns.CNAME:
 			rec.Value = v.Target
 			// 递归查询 CNAME 指向的 A/AAAA
-			childrenA, _, _ := queryDNS(v.Target, dns.TypeA, resolver, countryDB, cityDB, asnDB, depth+1)
-			childrenAAAA, _, _ := queryDNS(v.Target, dns.TypeAAAA, resolver, countryDB, cityDB, asnDB, depth+1)
+			childrenA, _, _, _ := queryDNS(v.Target, dns.TypeA, resolver, depth+1)
+			childrenAAAA, _, _, _ := queryDNS(v.Target, dns.TypeAAAA, resolver, depth+1)
 			rec.Children = append(rec.Children, childrenA...)
 			rec.Children = append(rec.Children, childrenAAAA...)
 		case *dns.MX:
 			rec.Value = fmt.Sprintf("%s (优先级 %d)", v.Mx, v.Preference)
-			childrenA, _, _ := queryDNS(v.Mx, dns.TypeA, resolver, countryDB, cityDB, asnDB, depth+1)
-			childrenAAAA, _, _ := queryDNS(v.Mx, dns.TypeAAAA, resolver, countryDB, cityDB, asnDB, depth+1)
+			childrenA, _, _, _ := queryDNS(v.Mx, dns.TypeA, resolver, depth+1)
+			childrenAAAA, _, _, _ := queryDNS(v.Mx, dns.TypeAAAA, resolver, depth+1)
 			rec.Children = append(rec.Children, childrenA...)
 			rec.Children = append(rec.Children, childrenAAAA...)
 		case *dns.NS:
 			rec.Value = v.Ns
-			childrenA, _, _ := queryDNS(v.Ns, dns.TypeA, resolver, countryDB, cityDB, asnDB, depth+1)
-			childrenAAAA, _, _ := queryDNS(v.Ns, dns.TypeAAAA, resolver, countryDB, cityDB, asnDB, depth+1)
+			childrenA, _, _, _ := queryDNS(v.Ns, dns.TypeA, resolver, depth+1)
+			childrenAAAA, _, _, _ := queryDNS(v.Ns, dns.TypeAAAA, resolver, depth+1)
 			rec.Children = append(rec.Children, childrenA...)
 			rec.Children = append(rec.Children, childrenAAAA...)
 		case *dns.TXT:
@@ -342,6 +609,56 @@ func queryDNS(domain string, qtype uint16, resolver string, asnDB *geoip2.Reader
 			rec.Value = fmt.Sprintf("%s %s", v.Ns, v.Mbox)
 		case *dns.CAA:
 			rec.Value = fmt.Sprintf("%d %s %s", v.Flag, v.Tag, v.Value)
+		case *dns.HTTPS:
+			rec.Value, rec.Fields = parseSVCB(&v.SVCB)
+		case *dns.SVCB:
+			rec.Value, rec.Fields = parseSVCB(v)
+		case *dns.SRV:
+			rec.Value = fmt.Sprintf("%d %d %d %s", v.Priority, v.Weight, v.Port, v.Target)
+			rec.Fields = map[string]string{
+				"priority": strconv.Itoa(int(v.Priority)),
+				"weight":   strconv.Itoa(int(v.Weight)),
+				"port":     strconv.Itoa(int(v.Port)),
+				"target":   v.Target,
+			}
+			if v.Target != "." {
+				childrenA, _, _, _ := queryDNS(v.Target, dns.TypeA, resolver, depth+1)
+				childrenAAAA, _, _, _ := queryDNS(v.Target, dns.TypeAAAA, resolver, depth+1)
+				rec.Children = append(rec.Children, childrenA...)
+				rec.Children = append(rec.Children, childrenAAAA...)
+			}
+		case *dns.DS:
+			rec.Value = fmt.Sprintf("%d %d %d %s", v.KeyTag, v.Algorithm, v.DigestType, v.Digest)
+			rec.Fields = map[string]string{
+				"key_tag":     strconv.Itoa(int(v.KeyTag)),
+				"algorithm":   dns.AlgorithmToString[v.Algorithm],
+				"digest_type": dns.HashToString[v.DigestType],
+				"digest":      v.Digest,
+			}
+		case *dns.DNSKEY:
+			role := "ZSK"
+			if v.Flags&dns.SEP != 0 {
+				role = "KSK"
+			}
+			rec.Value = fmt.Sprintf("%d %d %d %d", v.Flags, v.Protocol, v.Algorithm, v.KeyTag())
+			rec.Fields = map[string]string{
+				"key_tag":   strconv.Itoa(int(v.KeyTag())),
+				"flags":     strconv.Itoa(int(v.Flags)),
+				"algorithm": dns.AlgorithmToString[v.Algorithm],
+				"role":      role,
+			}
+		case *dns.NAPTR:
+			rec.Value = fmt.Sprintf("%d %d %q %q %q %s", v.Order, v.Preference, v.Flags, v.Service, v.Regexp, v.Replacement)
+			rec.Fields = map[string]string{
+				"order":       strconv.Itoa(int(v.Order)),
+				"preference":  strconv.Itoa(int(v.Preference)),
+				"flags":       v.Flags,
+				"service":     v.Service,
+				"regexp":      v.Regexp,
+				"replacement": v.Replacement,
+			}
+		case *dns.PTR:
+			rec.Value = v.Ptr
 		default:
 			rec.Value = rr.String()
 		}
@@ -351,13 +668,20 @@ func queryDNS(domain string, qtype uint16, resolver string, asnDB *geoip2.Reader
 		results = append(results, rec)
 	}
 
+	// 劫持评分
+	if qtype == dns.TypeA || qtype == dns.TypeAAAA {
+		analyzeHijack(domain, qtype, in, results, resolver, depth)
+	}
+
 	// 统计 TTL / 耗时信息
 	stats := models.DNSStatistics{
-		MinTTL:    minTTL,
 		MaxTTL:    maxTTL,
 		TotalTime: totalTime,
+		RTT:       rtt,
+		Records:   len(results),
 	}
 	if len(results) > 0 {
+		stats.MinTTL = minTTL
 		stats.AvgTTL = float64(ttlSum) / float64(len(results))
 		stats.MinTime, stats.MaxTime = durations[0], durations[0]
 		var total time.Duration
@@ -373,108 +697,127 @@ func queryDNS(domain string, qtype uint16, resolver string, asnDB *geoip2.Reader
 		stats.AvgTime = total / time.Duration(len(durations))
 	}
 
-	return results, stats, nil
+	return results, stats, response, nil
 }
 
-// lookupGeoASN 查询 IP 的国家、城市、ASN 和 ISP 信息
-// 优先使用缓存，减少重复查询
-func lookupGeoASN(ip net.IP, countryDB, cityDB, asnDB *geoip2.Reader) (string, string, string, string) {
-	if val, ok := geoCache.Load(ip.String()); ok {
-		data := val.([4]string)
-		return data[0], data[1], data[2], data[3]
+// parseSVCB 解析 HTTPS / SVCB 记录, 参数按 key 展开 (alpn / ech / ipv4hint / ipv6hint / port 等)
+func parseSVCB(v *dns.SVCB) (string, map[string]string) {
+	fields := map[string]string{
+		"priority": strconv.Itoa(int(v.Priority)),
+		"target":   v.Target,
 	}
-
-	// 默认值
-	country, city, asn, isp := "Unknown", "Unknown", "Unknown", "Unknown"
-
-	// 查询国家信息
-	if countryDB != nil {
-		if rec, err := countryDB.Country(ip); err == nil {
-			if n, ok := rec.Country.Names["en"]; ok {
-				country = n
-			}
-		}
+	// 优先级 0 为别名模式
+	if v.Priority == 0 {
+		fields["mode"] = "alias"
+	} else {
+		fields["mode"] = "service"
 	}
-
-	// 查询城市信息
-	if cityDB != nil {
-		if rec, err := cityDB.City(ip); err == nil {
-			if n := rec.City.Names["en"]; n != "" {
-				city = n
-			}
-			if n, ok := rec.Country.Names["en"]; ok {
-				country = n
-			}
-		}
+	params := make([]string, 0, len(v.Value))
+	for _, kv := range v.Value {
+		fields[kv.Key().String()] = kv.String()
+		params = append(params, kv.Key().String()+"="+kv.String())
 	}
-
-	// 查询 ASN / ISP 信息
-	if asnDB != nil {
-		if rec, err := asnDB.ASN(ip); err == nil {
-			asn = fmt.Sprintf("AS%d (%s)", rec.AutonomousSystemNumber, rec.AutonomousSystemOrganization)
-			isp = rec.AutonomousSystemOrganization
-		}
+	value := fmt.Sprintf("%d %s", v.Priority, v.Target)
+	if len(params) > 0 {
+		value += " " + strings.Join(params, " ")
 	}
-
-	geoCache.Store(ip.String(), [4]string{country, city, asn, isp})
-	return country, city, asn, isp
+	return value, fields
 }
 
-// detectCDN 判断 IP 是否属于 CDN 节点
-func detectCDN(asn string, ip net.IP, domain string) string {
-	for _, p := range models.CdnProviders {
-		if strings.Contains(asn, p) || strings.Contains(strings.ToLower(domain), strings.ToLower(p)) {
-			return "CDN"
-		}
+// buildDNSResponse 提取应答码、标志位和 EDNS 信息
+func buildDNSResponse(qtype string, in *dns.Msg, tcpFallback bool) models.DNSResponse {
+	response := models.DNSResponse{
+		Type:        qtype,
+		Rcode:       dns.RcodeToString[in.Rcode],
+		AA:          in.Authoritative,
+		AD:          in.AuthenticatedData,
+		RA:          in.RecursionAvailable,
+		Truncated:   in.Truncated,
+		TCPFallback: tcpFallback,
 	}
-	return "Origin"
-}
-
-// detectHijack 检测是否存在 DNS 劫持行为
-// 包括私网 IP、TTL 异常、NXDOMAIN 等
-func detectHijack(ip net.IP, msg *dns.Msg, ttl uint32) bool {
-	privateRanges := []string{
-		"0.0.0.0/8", "10.0.0.0/8", "127.0.0.0/8",
-		"169.254.0.0/16", "172.16.0.0/12", "192.168.0.0/16",
-		"100.64.0.0/10",
+	opt := in.IsEdns0()
+	if opt == nil {
+		return response
+	}
+	response.EDNS = &models.EDNSInfo{
+		Version:   opt.Version(),
+		UDPSize:   opt.UDPSize(),
+		DO:        opt.Do(),
+		ExtErrors: []string{},
 	}
-	for _, cidr := range privateRanges {
-		_, block, _ := net.ParseCIDR(cidr)
-		if block.Contains(ip) {
-			return true
+	for _, option := range opt.Option {
+		switch o := option.(type) {
+		case *dns.EDNS0_NSID:
+			// NSID 为十六进制编码
+			if decoded, decodeErr := hex.DecodeString(o.Nsid); decodeErr == nil {
+				response.EDNS.NSID = string(decoded)
+			} else {
+				response.EDNS.NSID = o.Nsid
+			}
+		case *dns.EDNS0_COOKIE:
+			response.EDNS.Cookie = true
+		case *dns.EDNS0_EDE:
+			response.EDNS.ExtErrors = append(response.EDNS.ExtErrors, strings.TrimSpace(fmt.Sprintf("%s %s", dns.ExtendedErrorCodeToString[o.InfoCode], o.ExtraText)))
 		}
 	}
+	return response
+}
 
-	// RcodeNameError 且有 Answer 也可能劫持
-	if msg.Rcode == dns.RcodeNameError && len(msg.Answer) > 0 {
-		return true
+// lookupGeoASN 查询 IP 的国家、城市、ASN 和 ISP 信息
+// 优先使用缓存，减少重复查询
+func lookupGeoASN(ip net.IP) cm.GeoInfo {
+	if geo, ok := geoCache.Get(ip.String()); ok {
+		return geo
 	}
 
-	// TTL 异常过低也认为可能劫持
-	if ttl > 0 && ttl < 10 {
-		return true
-	}
+	// 使用共享的 GeoIP 服务查询
+	geo := cs.LookupGeoIP(ip)
 
-	return false
+	geoCache.Set(ip.String(), geo)
+	return geo
 }
 
-// reversePTR 查询 IP 的 PTR 反向解析，使用并发限制
+// reversePTR 通过配置的解析器查询 IP 的 PTR 反向解析，使用并发限制
+// 有记录时按记录 TTL 缓存, 没有记录或查询失败时按 ptr_negative_ttl 缓存
 func reversePTR(ip net.IP) string {
-	if val, ok := ptrCache.Load(ip.String()); ok {
-		return val.(string)
+	if ptr, ok := ptrCache.Get(ip.String()); ok {
+		return ptr
 	}
 
 	// 并发限制
 	ptrSem <- struct{}{}
 	defer func() { <-ptrSem }()
 
-	names, err := net.LookupAddr(ip.String())
-	ptr := ""
-	if err == nil && len(names) > 0 {
-		ptr = strings.Join(names, ",")
+	negativeTTL := time.Duration(env.GetServerConfig().Collector.Dns.PtrNegativeTTL) * time.Second
+	arpa, err := dns.ReverseAddr(ip.String())
+	if err != nil {
+		return ""
+	}
+	m := new(dns.Msg)
+	m.SetQuestion(arpa, dns.TypePTR)
+	in, _, err := exchange(m, resolver)
+	if err != nil {
+		ptrCache.SetWithTTL(ip.String(), "", negativeTTL)
+		return ""
 	}
 
-	ptrCache.Store(ip.String(), ptr)
+	var names []string
+	var ttl uint32
+	for _, rr := range in.Answer {
+		if v, ok := rr.(*dns.PTR); ok {
+			names = append(names, v.Ptr)
+			if ttl == 0 || v.Hdr.Ttl < ttl {
+				ttl = v.Hdr.Ttl
+			}
+		}
+	}
+	if len(names) == 0 {
+		ptrCache.SetWithTTL(ip.String(), "", negativeTTL)
+		return ""
+	}
+
+	ptr := strings.Join(names, ",")
+	ptrCache.SetWithTTL(ip.String(), ptr, max(time.Duration(ttl)*time.Second, minPTRCacheTTL))
 	return ptr
 }
 
@@ -485,6 +828,49 @@ func saveDNSResult() common.GFError {
 	return nil
 }
 
+// 保存检查发现, 只保留严重程度不低于 minSeverity 的, 替换该检查项上一次的结果
+func saveFindings(name string, check string, findings []models.Finding, minSeverity string) {
+	var rows []models.GfnCollectorDNSFinding
+	now := time.Now()
+	for _, f := range findings {
+		if models.SeverityRank[f.Severity] < models.SeverityRank[minSeverity] {
+			continue
+		}
+		rows = append(rows, models.GfnCollectorDNSFinding{
+			ID:         util.GenerateId(),
+			Name:       name,
+			Check:      check,
+			Severity:   f.Severity,
+			Target:     f.Target,
+			Message:    f.Message,
+			CreateTime: now,
+		})
+	}
+	if err := dao.GetDNSDao().ReplaceFindings(name, check, rows); err != nil {
+		log.Error("保存DNS检查发现失败: ", err.GetMsg())
+	}
+}
+
+// 按检查项分组写入发现, skipped 中未能完成的检查项保留上次的结果
+func saveGroupedFindings(name string, checks []string, skipped []string, findings []models.Finding, minSeverity string) {
+	skippedSet := make(map[string]struct{}, len(skipped))
+	for _, check := range skipped {
+		skippedSet[check] = struct{}{}
+	}
+	for _, check := range checks {
+		if _, ok := skippedSet[check]; ok {
+			continue
+		}
+		var matched []models.Finding
+		for _, f := range findings {
+			if f.Check == check {
+				matched = append(matched, f)
+			}
+		}
+		saveFindings(name, check, matched, minSeverity)
+	}
+}
+
 // printDNSRecord 格式化打印 DNSRecord，包括递归子记录
 func printDNSRecord(rec models.DNSRecord, indent int) {
 	prefix := strings.Repeat("  ", indent)
@@ -505,7 +891,7 @@ func printDNSRecord(rec models.DNSRecord, indent int) {
 		fmt.Printf(" PTR=%s", rec.ReversePTR)
 	}
 	if rec.Hijacked {
-		fmt.Printf(" ⚠️劫持嫌疑")
+		fmt.Printf(" ⚠️劫持嫌疑(%d)", rec.HijackScore)
 	}
 	fmt.Printf(" 耗时=%v\n", rec.Duration)
 	for _, child := range rec.Children {
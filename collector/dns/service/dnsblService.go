@@ -0,0 +1,270 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/dns/models"
+	"github.com/GoFurry/gofurry-nav-collector/common/log"
+	"github.com/GoFurry/gofurry-nav-collector/common/util"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"github.com/miekg/dns"
+)
+
+// 查询黑名单的解析器, 未配置时使用默认解析器
+var dnsblResolver = initDNSBLResolver()
+
+// 同一 IP 的检查结果, 共享主机上的站点不重复查询
+var dnsblCache = initDNSBLCache()
+
+// 返回码网段, 其他地址视为解析器劫持等异常应答
+var dnsblListedBlock = &net.IPNet{IP: net.IPv4(127, 0, 0, 0), Mask: net.CIDRMask(8, 32)}
+
+// 黑名单拒绝查询时的返回码网段, 如 Spamhaus 拒绝公共解析器
+var dnsblErrorBlock = &net.IPNet{IP: net.IPv4(127, 255, 255, 0), Mask: net.CIDRMask(24, 32)}
+
+// ============== DNS解析 - IP 黑名单 ==============
+
+func initDNSBLResolver() *models.Resolver {
+	conf := env.GetServerConfig().Collector.Dns.DnsblResolver
+	if conf.Address == "" {
+		return nil
+	}
+	if conf.Name == "" {
+		conf.Name = "dnsbl"
+	}
+	r, err := parseResolver(conf)
+	if err != nil {
+		log.Error("黑名单解析器配置错误: ", err.GetMsg())
+		return nil
+	}
+	return r
+}
+
+func initDNSBLCache() *util.TTLCache[string, []models.DNSBLListing] {
+	ttl := env.GetServerConfig().Collector.Dns.DnsblCacheTTL
+	if ttl <= 0 {
+		ttl = 60
+	}
+	return util.NewTTLCache[string, []models.DNSBLListing](0, time.Duration(ttl)*time.Minute)
+}
+
+// 检查 A / AAAA 和 MX 主机的地址是否被列入黑名单, 未配置黑名单时返回 nil
+func checkDNSBL(results map[string][]models.DNSRecord) []models.DNSBLResult {
+	zones := env.GetServerConfig().Collector.Dns.DnsblZones
+	if len(zones) == 0 {
+		return nil
+	}
+
+	// 收集地址及其来源
+	sources := make(map[string][]string)
+	var ips []string
+	addIP := func(ip string, source string) {
+		if _, ok := sources[ip]; !ok {
+			ips = append(ips, ip)
+		}
+		for _, s := range sources[ip] {
+			if s == source {
+				return
+			}
+		}
+		sources[ip] = append(sources[ip], source)
+	}
+	var walk func(records []models.DNSRecord, source string)
+	walk = func(records []models.DNSRecord, source string) {
+		for _, rec := range records {
+			if rec.Type == "A" || rec.Type == "AAAA" {
+				addIP(rec.Value, orDefault(source, rec.Type))
+			}
+			walk(rec.Children, source)
+		}
+	}
+	walk(results["A"], "")
+	walk(results["AAAA"], "")
+	for _, mx := range results["MX"] {
+		// MX 记录值为 "主机 (优先级 n)"
+		host, _, _ := strings.Cut(mx.Value, " ")
+		host = strings.TrimSuffix(host, ".")
+		walk(mx.Children, "MX "+host)
+	}
+
+	res := make([]models.DNSBLResult, 0, len(ips))
+	for _, ip := range ips {
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			continue
+		}
+		// 保留地址不会被列入, 查询只会给黑名单增加无效请求
+		if matchBogon(parsed) != nil {
+			continue
+		}
+		item := lookupDNSBL(parsed, zones)
+		item.Sources = sources[ip]
+		res = append(res, item)
+	}
+	return res
+}
+
+// 全部黑名单都有明确应答, 有查询失败或被拒绝时结果不完整
+func dnsblComplete(results []models.DNSBLResult) bool {
+	for _, item := range results {
+		if len(item.Errors) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// 查询单个 IP 在各黑名单中的情况
+func lookupDNSBL(ip net.IP, zones []env.DnsblConfig) models.DNSBLResult {
+	res := models.DNSBLResult{IP: ip.String(), Listings: []models.DNSBLListing{}, Errors: []string{}}
+	if listings, ok := dnsblCache.Get(res.IP); ok {
+		res.Listings = listings
+		res.Listed = len(listings) > 0
+		return res
+	}
+
+	r := dnsblResolver
+	if r == nil {
+		r = resolver
+	}
+	reversed := dnsblReverse(ip)
+	var dnsblMu sync.Mutex
+	var dnsblWG sync.WaitGroup
+	for _, zone := range zones {
+		if ip.To4() == nil && !zone.IPv6 {
+			continue
+		}
+		dnsblWG.Add(1)
+		go func(zone env.DnsblConfig) {
+			defer dnsblWG.Done()
+			listing, errMsg := queryDNSBL(reversed, zone, r)
+			dnsblMu.Lock()
+			defer dnsblMu.Unlock()
+			if errMsg != "" {
+				res.Errors = append(res.Errors, orDefault(zone.Name, zone.Zone)+": "+errMsg)
+			}
+			if listing != nil {
+				res.Listings = append(res.Listings, *listing)
+			}
+		}(zone)
+	}
+	dnsblWG.Wait()
+	sort.Slice(res.Listings, func(i, j int) bool { return res.Listings[i].Zone < res.Listings[j].Zone })
+	sort.Strings(res.Errors)
+	res.Listed = len(res.Listings) > 0
+
+	// 有查询失败时不缓存, 下次重新查询
+	if len(res.Errors) == 0 {
+		dnsblCache.Set(res.IP, res.Listings)
+	}
+	return res
+}
+
+// 查询单个黑名单, 未列入时返回 nil
+func queryDNSBL(reversed string, zone env.DnsblConfig, r *models.Resolver) (*models.DNSBLListing, string) {
+	name := dns.Fqdn(reversed + strings.Trim(zone.Zone, "."))
+	m := new(dns.Msg)
+	m.SetQuestion(name, dns.TypeA)
+	in, _, err := exchange(m, r)
+	if err != nil {
+		return nil, err.Error()
+	}
+	switch in.Rcode {
+	case dns.RcodeSuccess:
+	case dns.RcodeNameError:
+		return nil, ""
+	default:
+		return nil, "rcode " + dns.RcodeToString[in.Rcode]
+	}
+
+	listing := models.DNSBLListing{Name: orDefault(zone.Name, zone.Zone), Zone: zone.Zone, Codes: []string{}, Policy: true}
+	known := models.DNSBLCodes[strings.ToLower(strings.Trim(zone.Zone, "."))]
+	for _, rr := range in.Answer {
+		a, ok := rr.(*dns.A)
+		if !ok {
+			continue
+		}
+		switch {
+		case dnsblErrorBlock.Contains(a.A):
+			return nil, "query refused with " + a.A.String()
+		case !dnsblListedBlock.Contains(a.A):
+			return nil, "unexpected answer " + a.A.String()
+		}
+		code := a.A.String()
+		if meaning, ok := known[code]; ok {
+			listing.Codes = append(listing.Codes, code+" "+meaning.Label)
+			listing.Policy = listing.Policy && meaning.Policy
+		} else {
+			listing.Codes = append(listing.Codes, code)
+			listing.Policy = false
+		}
+	}
+	if len(listing.Codes) == 0 {
+		return nil, ""
+	}
+	sort.Strings(listing.Codes)
+
+	// 列入时再查 TXT 获取说明
+	m = new(dns.Msg)
+	m.SetQuestion(name, dns.TypeTXT)
+	if txtIn, _, txtErr := exchange(m, r); txtErr == nil {
+		var reasons []string
+		for _, rr := range txtIn.Answer {
+			if txt, ok := rr.(*dns.TXT); ok {
+				reasons = append(reasons, strings.Join(txt.Txt, ""))
+			}
+		}
+		listing.Reason = strings.Join(reasons, " | ")
+	}
+	return &listing, ""
+}
+
+// 反转地址作为查询前缀, IPv4 按字节, IPv6 按半字节, 以点结尾
+func dnsblReverse(ip net.IP) string {
+	rev, err := dns.ReverseAddr(ip.String())
+	if err != nil {
+		return ""
+	}
+	rev = strings.TrimSuffix(rev, "in-addr.arpa.")
+	return strings.TrimSuffix(rev, "ip6.arpa.")
+}
+
+// 列入黑名单转为发现
+// 网站地址被列入多为共享主机被入侵, 访问者会看到浏览器警告; 只有策略性列入时不代表地址有问题
+func dnsblFindings(results []models.DNSBLResult) []models.Finding {
+	findings := []models.Finding{}
+	for _, item := range results {
+		if !item.Listed {
+			continue
+		}
+		web := false
+		for _, source := range item.Sources {
+			if !strings.HasPrefix(source, "MX ") {
+				web = true
+			}
+		}
+		for _, listing := range item.Listings {
+			severity := models.SeverityMedium
+			switch {
+			case listing.Policy:
+				severity = models.SeverityLow
+			case web:
+				severity = models.SeverityHigh
+			}
+			message := fmt.Sprintf("%s (%s) listed on %s [%s]", item.IP, strings.Join(item.Sources, ", "), listing.Name, strings.Join(listing.Codes, ", "))
+			if listing.Reason != "" {
+				message += ": " + listing.Reason
+			}
+			findings = append(findings, models.Finding{Check: models.FindingCheckDNSBL, Severity: severity, Target: item.IP, Message: message})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return models.SeverityRank[findings[i].Severity] > models.SeverityRank[findings[j].Severity]
+	})
+	return findings
+}
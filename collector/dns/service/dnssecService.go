@@ -0,0 +1,556 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/dns/models"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"github.com/miekg/dns"
+)
+
+// 已验证区域的 DNSKEY 缓存时间, 根和顶级域在多个站点间复用
+const dnssecKeyCacheTTL = time.Hour
+
+// 已验证的区域密钥
+type dnssecZoneKeys struct {
+	keys   []*dns.DNSKEY
+	step   models.DNSSECZone
+	expire time.Time
+}
+
+var dnssecKeyCache sync.Map
+
+// ============== DNS解析 - DNSSEC 验证 ==============
+
+// 从根信任锚开始逐级验证 DS / DNSKEY, 再验证各记录类型的应答
+func validateDNSSEC(domain string) models.DNSSECResult {
+	res := models.DNSSECResult{
+		Status:   models.DNSSECSecure,
+		Warnings: []string{},
+		Chain:    []models.DNSSECZone{},
+		RRsets:   make(map[string]models.DNSSECRRset),
+	}
+	name := dns.Fqdn(domain)
+
+	// 根区
+	var anchors []*dns.DS
+	for _, a := range models.RootTrustAnchors {
+		anchors = append(anchors, &dns.DS{
+			Hdr:        dns.RR_Header{Name: ".", Rrtype: dns.TypeDS, Class: dns.ClassINET},
+			KeyTag:     a.KeyTag,
+			Algorithm:  a.Algorithm,
+			DigestType: a.DigestType,
+			Digest:     a.Digest,
+		})
+	}
+	zone := "."
+	keys, step := validateZoneKeys(zone, anchors, &res)
+	res.Chain = append(res.Chain, step)
+	if step.Status != models.DNSSECSecure {
+		res.Status, res.Reason = step.Status, step.Reason
+		return res
+	}
+
+	// 逐级查找区域切分点
+	labels := dns.SplitDomainName(name)
+	for i := len(labels) - 1; i >= 0; i-- {
+		child := dns.Fqdn(strings.Join(labels[i:], "."))
+		ds, status, reason := lookupDS(child, zone, keys, &res)
+		switch {
+		case len(ds) > 0:
+			keys, step = validateZoneKeys(child, ds, &res)
+			res.Chain = append(res.Chain, step)
+			if step.Status != models.DNSSECSecure {
+				res.Status, res.Reason = step.Status, step.Reason
+				return res
+			}
+			zone = child
+		case status == "":
+			// 不是区域切分点, 仍由上级区域签名
+			continue
+		default:
+			res.Chain = append(res.Chain, models.DNSSECZone{Zone: child, Status: status, Reason: reason})
+			res.Status, res.Reason = status, reason
+			return res
+		}
+	}
+
+	// 验证各记录类型的应答, DS / DNSKEY 已在验证链中处理, SRV 不在域名本身
+	for _, rt := range recordTypes {
+		if rt.Type == dns.TypeDS || rt.Type == dns.TypeDNSKEY || rt.Type == dns.TypeSRV {
+			continue
+		}
+		rrset := validateAnswer(name, rt, zone, keys, &res)
+		res.RRsets[rt.Name] = rrset
+		if models.DNSSECSeverity[rrset.Status] > models.DNSSECSeverity[res.Status] {
+			res.Status = rrset.Status
+			res.Reason = rt.Name + ": " + rrset.Reason
+		}
+	}
+	return res
+}
+
+// 按验证结果标记记录的 DNSSEC 字段
+func markDNSSEC(results map[string][]models.DNSRecord, res models.DNSSECResult) {
+	for k, records := range results {
+		secure := res.RRsets[k].Status == models.DNSSECSecure
+		for i := range records {
+			records[i].DNSSEC = secure
+		}
+	}
+}
+
+// 带 DO 和 CD 位的查询, 拿到签名且不让解析器过滤验证失败的数据
+func dnssecQuery(name string, qtype uint16) (*dns.Msg, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(name, qtype)
+	m.SetEdns0(4096, true)
+	m.CheckingDisabled = true
+	in, _, err := exchange(m, resolver)
+	if err != nil {
+		return nil, err
+	}
+	if in.Rcode != dns.RcodeSuccess && in.Rcode != dns.RcodeNameError {
+		return nil, fmt.Errorf("rcode %s", dns.RcodeToString[in.Rcode])
+	}
+	return in, nil
+}
+
+// 用上级区域传下来的 DS 验证区域的 DNSKEY
+func validateZoneKeys(zone string, ds []*dns.DS, res *models.DNSSECResult) ([]*dns.DNSKEY, models.DNSSECZone) {
+	if cached, ok := dnssecKeyCache.Load(zone); ok {
+		entry := cached.(dnssecZoneKeys)
+		if time.Now().Before(entry.expire) && sameDS(entry.step.DSTags, ds) {
+			return entry.keys, entry.step
+		}
+	}
+
+	step := models.DNSSECZone{Zone: zone, DSTags: []uint16{}, KeyTags: []uint16{}, Algorithms: []string{}}
+	for _, d := range ds {
+		step.DSTags = append(step.DSTags, d.KeyTag)
+	}
+
+	in, err := dnssecQuery(zone, dns.TypeDNSKEY)
+	if err != nil {
+		step.Status, step.Reason = models.DNSSECIndeterminate, "DNSKEY query failed: "+err.Error()
+		return nil, step
+	}
+	rrset, sigs := extractRRset(in.Answer, zone, dns.TypeDNSKEY)
+	var keys []*dns.DNSKEY
+	algorithms := make(map[string]struct{})
+	for _, rr := range rrset {
+		key := rr.(*dns.DNSKEY)
+		keys = append(keys, key)
+		step.KeyTags = append(step.KeyTags, key.KeyTag())
+		algorithms[dns.AlgorithmToString[key.Algorithm]] = struct{}{}
+	}
+	for a := range algorithms {
+		step.Algorithms = append(step.Algorithms, a)
+	}
+	sort.Strings(step.Algorithms)
+	if len(keys) == 0 {
+		step.Status, step.Reason = models.DNSSECBogus, "DS present but no DNSKEY"
+		return nil, step
+	}
+
+	// 找到与 DS 匹配的 KSK
+	var ksks []*dns.DNSKEY
+	supported := false
+	for _, d := range ds {
+		if !models.DNSSECAlgorithms[d.Algorithm] || !models.DSDigestTypes[d.DigestType] {
+			continue
+		}
+		supported = true
+		for _, key := range keys {
+			if key.KeyTag() != d.KeyTag || key.Algorithm != d.Algorithm {
+				continue
+			}
+			if digest := key.ToDS(d.DigestType); digest != nil && strings.EqualFold(digest.Digest, d.Digest) {
+				ksks = append(ksks, key)
+			}
+		}
+	}
+	if !supported {
+		step.Status, step.Reason = models.DNSSECInsecure, "algorithm unsupported"
+		return nil, step
+	}
+	if len(ksks) == 0 {
+		step.Status, step.Reason = models.DNSSECBogus, "no DNSKEY matches DS"
+		return nil, step
+	}
+
+	// KSK 签名 DNSKEY 集合
+	status, reason, _ := verifyRRset(rrset, sigs, ksks, res)
+	step.Status, step.Reason = status, reason
+	if status != models.DNSSECSecure {
+		step.Reason = "DNSKEY " + reason
+		return nil, step
+	}
+
+	dnssecKeyCache.Store(zone, dnssecZoneKeys{keys: keys, step: step, expire: time.Now().Add(dnssecKeyCacheTTL)})
+	return keys, step
+}
+
+// 缓存的 DS 是否与本次一致
+func sameDS(tags []uint16, ds []*dns.DS) bool {
+	if len(tags) != len(ds) {
+		return false
+	}
+	for i, d := range ds {
+		if tags[i] != d.KeyTag {
+			return false
+		}
+	}
+	return true
+}
+
+// 在上级区域中查询 DS
+// 有 DS 时返回已验证的 DS; 不是区域切分点时状态为空; 未签名委派返回 insecure
+func lookupDS(child string, parent string, parentKeys []*dns.DNSKEY, res *models.DNSSECResult) ([]*dns.DS, string, string) {
+	in, err := dnssecQuery(child, dns.TypeDS)
+	if err != nil {
+		return nil, models.DNSSECIndeterminate, "DS query for " + child + " failed: " + err.Error()
+	}
+	if in.Rcode == dns.RcodeNameError {
+		return nil, "", ""
+	}
+
+	rrset, sigs := extractRRset(in.Answer, child, dns.TypeDS)
+	if len(rrset) > 0 {
+		status, reason, _ := verifyRRset(rrset, sigs, parentKeys, res)
+		if status != models.DNSSECSecure {
+			return nil, status, "DS for " + child + " " + reason
+		}
+		var ds []*dns.DS
+		for _, rr := range rrset {
+			ds = append(ds, rr.(*dns.DS))
+		}
+		return ds, "", ""
+	}
+
+	// 没有 DS, 通过 SOA 判断是否为区域切分点
+	soa, err := dnssecQuery(child, dns.TypeSOA)
+	if err != nil {
+		return nil, models.DNSSECIndeterminate, "SOA query for " + child + " failed: " + err.Error()
+	}
+	if soaSet, _ := extractRRset(soa.Answer, child, dns.TypeSOA); len(soaSet) == 0 {
+		return nil, "", ""
+	}
+
+	// 未签名的委派, 需要上级区域签名的 NSEC / NSEC3 证明没有 DS
+	if !verifyNoDS(child, in.Ns, parentKeys, res) {
+		return nil, models.DNSSECBogus, "missing DS for " + child + " without valid denial proof"
+	}
+	return nil, models.DNSSECInsecure, "missing DS for " + child + " (unsigned delegation)"
+}
+
+// 验证 NSEC / NSEC3 证明 child 不存在 DS
+func verifyNoDS(child string, authority []dns.RR, parentKeys []*dns.DNSKEY, res *models.DNSSECResult) bool {
+	for _, rr := range authority {
+		switch v := rr.(type) {
+		case *dns.NSEC:
+			if !strings.EqualFold(v.Hdr.Name, child) || hasType(v.TypeBitMap, dns.TypeDS) {
+				continue
+			}
+		case *dns.NSEC3:
+			// 匹配且没有 DS, 或 opt-out 区间覆盖
+			if v.Match(child) {
+				if hasType(v.TypeBitMap, dns.TypeDS) {
+					continue
+				}
+			} else if !(v.Cover(child) && v.Flags&1 == 1) {
+				continue
+			}
+		default:
+			continue
+		}
+		rrset, sigs := extractRRset(authority, rr.Header().Name, rr.Header().Rrtype)
+		if status, _, _ := verifyRRset(rrset, sigs, parentKeys, res); status == models.DNSSECSecure {
+			return true
+		}
+	}
+	return false
+}
+
+// 验证目标区域中某个记录类型的应答
+func validateAnswer(name string, rt models.RecordType, zone string, keys []*dns.DNSKEY, res *models.DNSSECResult) models.DNSSECRRset {
+	rrsetRes := models.DNSSECRRset{Type: rt.Name, Signer: zone}
+	in, err := dnssecQuery(name, rt.Type)
+	if err != nil {
+		rrsetRes.Status, rrsetRes.Reason = models.DNSSECIndeterminate, "query failed: "+err.Error()
+		return rrsetRes
+	}
+
+	// 优先取目标类型, 没有时取 CNAME
+	rrset, sigs := extractRRset(in.Answer, name, rt.Type)
+	if len(rrset) == 0 && rt.Type != dns.TypeCNAME {
+		rrset, sigs = extractRRset(in.Answer, name, dns.TypeCNAME)
+		if len(rrset) > 0 {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("%s: CNAME target %s not validated", rt.Name, rrset[0].(*dns.CNAME).Target))
+		}
+	}
+
+	if len(rrset) > 0 {
+		var expiration time.Time
+		rrsetRes.Status, rrsetRes.Reason, expiration = verifyRRset(rrset, sigs, keys, res)
+		rrsetRes.Expiration = expiration
+		return rrsetRes
+	}
+
+	// 没有记录, 验证否定应答: NSEC / NSEC3 须通过签名验证, 并且确实证明了该名称或类型不存在
+	nsecs, nsec3s, expiration := verifiedDenials(in.Ns, keys, res)
+	if len(nsecs) == 0 && len(nsec3s) == 0 {
+		rrsetRes.Status, rrsetRes.Reason = models.DNSSECBogus, "missing denial of existence"
+		return rrsetRes
+	}
+	nxdomain := in.Rcode == dns.RcodeNameError
+	if proveDenialNSEC(name, rt.Type, nxdomain, nsecs) || proveDenialNSEC3(name, rt.Type, zone, nxdomain, nsec3s) {
+		rrsetRes.Status, rrsetRes.Reason, rrsetRes.Expiration = models.DNSSECSecure, "authenticated denial of existence", expiration
+		return rrsetRes
+	}
+	rrsetRes.Status, rrsetRes.Reason = models.DNSSECBogus, "denial of existence does not cover "+name
+	return rrsetRes
+}
+
+// 返回 Authority 段中签名有效的 NSEC / NSEC3, 以及最早的签名过期时间
+func verifiedDenials(authority []dns.RR, keys []*dns.DNSKEY, res *models.DNSSECResult) ([]*dns.NSEC, []*dns.NSEC3, time.Time) {
+	var nsecs []*dns.NSEC
+	var nsec3s []*dns.NSEC3
+	var expiration time.Time
+	checked := make(map[string]bool)
+	for _, rr := range authority {
+		t := rr.Header().Rrtype
+		if t != dns.TypeNSEC && t != dns.TypeNSEC3 {
+			continue
+		}
+		key := strings.ToLower(rr.Header().Name) + "/" + dns.TypeToString[t]
+		valid, seen := checked[key]
+		if !seen {
+			rrset, sigs := extractRRset(authority, rr.Header().Name, t)
+			status, _, exp := verifyRRset(rrset, sigs, keys, res)
+			valid = status == models.DNSSECSecure
+			checked[key] = valid
+			if valid && (expiration.IsZero() || exp.Before(expiration)) {
+				expiration = exp
+			}
+		}
+		if !valid {
+			continue
+		}
+		switch v := rr.(type) {
+		case *dns.NSEC:
+			nsecs = append(nsecs, v)
+		case *dns.NSEC3:
+			nsec3s = append(nsec3s, v)
+		}
+	}
+	return nsecs, nsec3s, expiration
+}
+
+// NSEC 否定证明, RFC 4035 5.4
+// NODATA: 同名 NSEC 的位图中没有该类型; NXDOMAIN: 有 NSEC 覆盖该名称, 且最近祖先下的通配符不存在
+// 通配符 NODATA: 有 NSEC 覆盖该名称, 且通配符的 NSEC 位图中没有该类型
+func proveDenialNSEC(name string, qtype uint16, nxdomain bool, nsecs []*dns.NSEC) bool {
+	if !nxdomain {
+		for _, n := range nsecs {
+			if strings.EqualFold(n.Hdr.Name, name) {
+				return !hasType(n.TypeBitMap, qtype) && !hasType(n.TypeBitMap, dns.TypeCNAME)
+			}
+		}
+	}
+
+	var cover *dns.NSEC
+	for _, n := range nsecs {
+		if nsecCovers(n, name) {
+			cover = n
+			break
+		}
+	}
+	if cover == nil {
+		return false
+	}
+	// 最近祖先取名称与 NSEC 两端的最长公共祖先
+	encloser := commonAncestor(name, cover.Hdr.Name)
+	if other := commonAncestor(name, cover.NextDomain); dns.CountLabel(other) > dns.CountLabel(encloser) {
+		encloser = other
+	}
+	wildcard := "*." + encloser
+	if encloser == "." {
+		wildcard = "*."
+	}
+	for _, n := range nsecs {
+		if nxdomain && nsecCovers(n, wildcard) {
+			return true
+		}
+		if strings.EqualFold(n.Hdr.Name, wildcard) {
+			// 通配符存在: NXDOMAIN 不成立, NODATA 要求通配符没有该类型
+			return !nxdomain && !hasType(n.TypeBitMap, qtype) && !hasType(n.TypeBitMap, dns.TypeCNAME)
+		}
+	}
+	return false
+}
+
+// NSEC3 否定证明, RFC 5155 8.4 - 8.7
+// NODATA: 哈希匹配的 NSEC3 位图中没有该类型; 其他情况需要最近祖先证明: 祖先匹配, 下一级名称被覆盖, 通配符被覆盖
+func proveDenialNSEC3(name string, qtype uint16, zone string, nxdomain bool, nsec3s []*dns.NSEC3) bool {
+	if len(nsec3s) == 0 {
+		return false
+	}
+	if !nxdomain {
+		for _, n := range nsec3s {
+			if n.Match(name) {
+				return !hasType(n.TypeBitMap, qtype) && !hasType(n.TypeBitMap, dns.TypeCNAME)
+			}
+		}
+	}
+
+	labels := dns.SplitDomainName(name)
+	for i := 1; i < len(labels); i++ {
+		encloser := dns.Fqdn(strings.Join(labels[i:], "."))
+		if !dns.IsSubDomain(zone, encloser) {
+			break
+		}
+		matched := false
+		for _, n := range nsec3s {
+			if n.Match(encloser) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		nextCloser := dns.Fqdn(strings.Join(labels[i-1:], "."))
+		wildcard := "*." + encloser
+		closerCovered, wildcardOK := false, false
+		for _, n := range nsec3s {
+			if n.Cover(nextCloser) {
+				closerCovered = true
+			}
+			if nxdomain && n.Cover(wildcard) {
+				wildcardOK = true
+			}
+			if !nxdomain && n.Match(wildcard) && !hasType(n.TypeBitMap, qtype) && !hasType(n.TypeBitMap, dns.TypeCNAME) {
+				wildcardOK = true
+			}
+		}
+		return closerCovered && wildcardOK
+	}
+	return false
+}
+
+// NSEC 是否覆盖名称 (按规范顺序在 owner 与 next 之间), 最后一条 NSEC 的 next 回到区域顶点
+func nsecCovers(n *dns.NSEC, name string) bool {
+	owner, next := n.Hdr.Name, n.NextDomain
+	if canonicalCompare(owner, next) < 0 {
+		return canonicalCompare(owner, name) < 0 && canonicalCompare(name, next) < 0
+	}
+	return canonicalCompare(owner, name) < 0 || canonicalCompare(name, next) < 0
+}
+
+// 按 RFC 4034 6.1 的规范顺序比较域名: 从最右边的标签开始, 忽略大小写逐字节比较
+func canonicalCompare(a string, b string) int {
+	la := dns.SplitDomainName(strings.ToLower(a))
+	lb := dns.SplitDomainName(strings.ToLower(b))
+	for i, j := len(la)-1, len(lb)-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		if c := strings.Compare(la[i], lb[j]); c != 0 {
+			return c
+		}
+	}
+	return len(la) - len(lb)
+}
+
+// 两个名称的最长公共祖先
+func commonAncestor(a string, b string) string {
+	n := dns.CompareDomainName(a, b)
+	labels := dns.SplitDomainName(a)
+	if n == 0 || len(labels) == 0 {
+		return "."
+	}
+	return dns.Fqdn(strings.Join(labels[len(labels)-n:], "."))
+}
+
+// 用给定密钥验证 RRset, 任一签名有效即通过, 返回状态、原因和签名过期时间
+func verifyRRset(rrset []dns.RR, sigs []*dns.RRSIG, keys []*dns.DNSKEY, res *models.DNSSECResult) (string, string, time.Time) {
+	if len(sigs) == 0 {
+		return models.DNSSECBogus, "missing signature", time.Time{}
+	}
+
+	reason := "no key for signature"
+	unsupported := true
+	now := time.Now()
+	for _, sig := range sigs {
+		if !models.DNSSECAlgorithms[sig.Algorithm] {
+			reason = "algorithm unsupported: " + dns.AlgorithmToString[sig.Algorithm]
+			continue
+		}
+		unsupported = false
+		for _, key := range keys {
+			if key.KeyTag() != sig.KeyTag || key.Algorithm != sig.Algorithm || !strings.EqualFold(key.Hdr.Name, sig.SignerName) {
+				continue
+			}
+			if err := sig.Verify(key, rrset); err != nil {
+				reason = "signature invalid: " + err.Error()
+				continue
+			}
+			expiration := time.Unix(int64(sig.Expiration), 0)
+			if !sig.ValidityPeriod(now) {
+				if now.After(expiration) {
+					reason = "signature expired at " + expiration.UTC().Format(time.RFC3339)
+				} else {
+					reason = "signature not yet valid"
+				}
+				continue
+			}
+			// 即将过期告警
+			warnDays := env.GetServerConfig().Collector.Dns.DnssecExpiryWarn
+			if warnDays <= 0 {
+				warnDays = 7
+			}
+			if expiration.Sub(now) < time.Duration(warnDays)*24*time.Hour {
+				res.Warnings = append(res.Warnings, fmt.Sprintf("%s/%s signature expires at %s",
+					rrset[0].Header().Name, dns.TypeToString[sig.TypeCovered], expiration.UTC().Format(time.RFC3339)))
+			}
+			return models.DNSSECSecure, "", expiration
+		}
+	}
+	if unsupported {
+		return models.DNSSECInsecure, reason, time.Time{}
+	}
+	return models.DNSSECBogus, reason, time.Time{}
+}
+
+// 从记录列表中取出指定名称和类型的 RRset 及其签名
+func extractRRset(rrs []dns.RR, name string, qtype uint16) ([]dns.RR, []*dns.RRSIG) {
+	var rrset []dns.RR
+	var sigs []*dns.RRSIG
+	for _, rr := range rrs {
+		if !strings.EqualFold(rr.Header().Name, name) {
+			continue
+		}
+		if sig, ok := rr.(*dns.RRSIG); ok {
+			if sig.TypeCovered == qtype {
+				sigs = append(sigs, sig)
+			}
+			continue
+		}
+		if rr.Header().Rrtype == qtype {
+			rrset = append(rrset, rr)
+		}
+	}
+	return rrset, sigs
+}
+
+// NSEC 类型位图中是否包含某类型
+func hasType(bitmap []uint16, qtype uint16) bool {
+	for _, t := range bitmap {
+		if t == qtype {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/dns/models"
+	"github.com/GoFurry/gofurry-nav-collector/common/log"
+	cs "github.com/GoFurry/gofurry-nav-collector/common/service"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"github.com/miekg/dns"
+)
+
+// 发送 ECS 的解析器, 未配置时使用默认解析器
+var ecsResolver = initECSResolver()
+
+// 模拟的客户端网段
+var ecsSubnets = initECSSubnets()
+
+type ecsSubnet struct {
+	name    string
+	block   *net.IPNet
+	country string
+}
+
+// ============== DNS解析 - ECS ==============
+
+func initECSResolver() *models.Resolver {
+	conf := env.GetServerConfig().Collector.Dns.EcsResolver
+	if conf.Address == "" {
+		return nil
+	}
+	if conf.Name == "" {
+		conf.Name = "ecs"
+	}
+	r, err := parseResolver(conf)
+	if err != nil {
+		log.Error("ECS 解析器配置错误: ", err.GetMsg())
+		return nil
+	}
+	return r
+}
+
+func initECSSubnets() []ecsSubnet {
+	var subnets []ecsSubnet
+	for _, conf := range env.GetServerConfig().Collector.Dns.EcsSubnets {
+		_, block, err := net.ParseCIDR(strings.TrimSpace(conf.Subnet))
+		if err != nil {
+			log.Warn("ECS 网段无效: " + conf.Subnet)
+			continue
+		}
+		subnets = append(subnets, ecsSubnet{name: orDefault(conf.Name, block.String()), block: block, country: conf.Country})
+	}
+	return subnets
+}
+
+// 以不同客户端网段查询 A / AAAA, 对比各地区拿到的应答
+// 未配置网段时返回 nil
+func checkECS(domain string) *models.ECSResult {
+	if len(ecsSubnets) == 0 {
+		return nil
+	}
+	r := ecsResolver
+	if r == nil {
+		r = resolver
+	}
+
+	qtypes := []uint16{dns.TypeA, dns.TypeAAAA}
+	answers := make([]models.ECSAnswer, len(ecsSubnets)*len(qtypes))
+	var ecsWG sync.WaitGroup
+	for i, subnet := range ecsSubnets {
+		for j, qtype := range qtypes {
+			ecsWG.Add(1)
+			go func(idx int, subnet ecsSubnet, qtype uint16) {
+				defer ecsWG.Done()
+				answers[idx] = queryECS(domain, qtype, subnet, r)
+			}(i*len(qtypes)+j, subnet, qtype)
+		}
+	}
+	ecsWG.Wait()
+
+	res := &models.ECSResult{Resolver: r.Name, NotLocal: []string{}}
+	sets := make(map[string]struct{})
+	for _, ans := range answers {
+		// 没有该类型记录的应答不参与对比
+		if ans.Error != "" || len(ans.Answers) == 0 {
+			res.Answers = append(res.Answers, ans)
+			continue
+		}
+		ips := make([]string, 0, len(ans.Answers))
+		for _, addr := range ans.Answers {
+			ips = append(ips, addr.IP)
+		}
+		sort.Strings(ips)
+		sets[ans.Type+" "+strings.Join(ips, ",")] = struct{}{}
+		if !ans.Local && ans.Country != "" {
+			res.NotLocal = append(res.NotLocal, ans.Name+" "+ans.Type)
+		}
+		res.Answers = append(res.Answers, ans)
+	}
+	res.Distinct = len(sets)
+	// A 与 AAAA 各算一组, 超过记录类型数说明同一类型在不同网段下应答不同
+	types := make(map[string]struct{})
+	for key := range sets {
+		types[strings.SplitN(key, " ", 2)[0]] = struct{}{}
+	}
+	res.GeoDependent = res.Distinct > len(types)
+	return res
+}
+
+// 带 ECS 选项查询单个网段
+func queryECS(domain string, qtype uint16, subnet ecsSubnet, r *models.Resolver) models.ECSAnswer {
+	ans := models.ECSAnswer{
+		Name:      subnet.name,
+		Subnet:    subnet.block.String(),
+		Country:   subnet.country,
+		Type:      dns.TypeToString[qtype],
+		Answers:   []models.ECSAddress{},
+		Countries: []string{},
+	}
+	if ans.Country == "" {
+		if geo := lookupGeoASN(subnet.block.IP); geo.Country != "Unknown" {
+			ans.Country = geo.Country
+		}
+	}
+
+	ones, _ := subnet.block.Mask.Size()
+	option := &dns.EDNS0_SUBNET{
+		Code:          dns.EDNS0SUBNET,
+		Family:        1,
+		SourceNetmask: uint8(ones),
+		Address:       subnet.block.IP,
+	}
+	if subnet.block.IP.To4() == nil {
+		option.Family = 2
+	}
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(domain), qtype)
+	m.SetEdns0(4096, false)
+	opt := m.IsEdns0()
+	opt.Option = append(opt.Option, option)
+
+	in, rtt, err := exchange(m, r)
+	ans.RTT = rtt
+	if err != nil {
+		ans.Error = err.Error()
+		return ans
+	}
+	if in.Rcode != dns.RcodeSuccess {
+		ans.Error = dns.RcodeToString[in.Rcode]
+		return ans
+	}
+	if respOpt := in.IsEdns0(); respOpt != nil {
+		for _, o := range respOpt.Option {
+			if e, ok := o.(*dns.EDNS0_SUBNET); ok {
+				ans.Scope = e.SourceScope
+			}
+		}
+	}
+
+	// 解析链, 用于按 CNAME 识别服务商
+	chain := []string{domain}
+	for _, rr := range in.Answer {
+		if c, ok := rr.(*dns.CNAME); ok {
+			chain = append(chain, c.Target)
+		}
+	}
+	countries := make(map[string]struct{})
+	local := true
+	for _, rr := range in.Answer {
+		var ip net.IP
+		switch v := rr.(type) {
+		case *dns.A:
+			ip = v.A
+		case *dns.AAAA:
+			ip = v.AAAA
+		default:
+			continue
+		}
+		geo := lookupGeoASN(ip)
+		provider := cs.DetectProvider(ip, geo.ASNumber, chain)
+		ans.Answers = append(ans.Answers, models.ECSAddress{
+			IP:       ip.String(),
+			Country:  geo.Country,
+			City:     geo.City,
+			ASN:      geo.ASN,
+			Provider: provider.Name,
+		})
+		if _, ok := countries[geo.Country]; !ok {
+			countries[geo.Country] = struct{}{}
+			ans.Countries = append(ans.Countries, geo.Country)
+		}
+		if !strings.EqualFold(geo.Country, ans.Country) {
+			local = false
+		}
+	}
+	ans.Local = local && len(ans.Answers) > 0 && ans.Country != ""
+	if !ans.Local && len(ans.Answers) > 0 && ans.Country != "" {
+		log.Debug(fmt.Sprintf("%s %s 网段 %s 应答来自 %v", domain, ans.Type, ans.Name, ans.Countries))
+	}
+	return ans
+}
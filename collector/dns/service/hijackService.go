@@ -0,0 +1,230 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/dns/models"
+	"github.com/GoFurry/gofurry-nav-collector/common"
+	"github.com/GoFurry/gofurry-nav-collector/common/log"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"github.com/miekg/dns"
+	"gopkg.in/yaml.v2"
+)
+
+// 劫持检测的可信解析器, 未配置时为 nil
+var hijackBaseline = initHijackBaseline()
+
+// 保留 / 未分配地址段 文件更新后自动重新加载, 未配置时使用内置列表
+var bogonBlocks = parseBlocks(models.BogonRanges)
+var bogonModTime time.Time
+var bogonRWLock sync.RWMutex
+
+// 可信解析器的应答
+type baselineAnswer struct {
+	ok       bool                // 查询成功
+	nxdomain bool                // 域名不存在
+	ips      map[string]struct{} // 应答 IP
+	asns     map[uint]struct{}   // 应答 IP 所属 ASN
+}
+
+// ============== DNS解析 - 劫持检测 ==============
+
+func initHijackBaseline() *models.Resolver {
+	conf := env.GetServerConfig().Collector.Dns.HijackBaseline
+	if conf.Address == "" {
+		return nil
+	}
+	if conf.Name == "" {
+		conf.Name = "baseline"
+	}
+	r, err := parseResolver(conf)
+	if err != nil {
+		log.Error("劫持检测可信解析器配置错误: ", err.GetMsg())
+		return nil
+	}
+	return r
+}
+
+func parseBlocks(cidrs []string) []*net.IPNet {
+	var blocks []*net.IPNet
+	for _, cidr := range cidrs {
+		_, block, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Warn("IP 段无效: " + cidr)
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// 加载保留地址段数据, 文件未变化时跳过, 加载失败时继续使用已有数据
+func loadBogonRanges() common.GFError {
+	path := env.GetServerConfig().Collector.Dns.BogonPath
+	if path == "" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return common.NewServiceError("读取保留地址段数据文件失败: " + err.Error())
+	}
+
+	bogonRWLock.RLock()
+	unchanged := info.ModTime().Equal(bogonModTime)
+	bogonRWLock.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return common.NewServiceError("读取保留地址段数据文件失败: " + err.Error())
+	}
+	var bogonFile models.BogonFile
+	if err = yaml.Unmarshal(fileBytes, &bogonFile); err != nil {
+		return common.NewServiceError("解析保留地址段数据文件失败: " + err.Error())
+	}
+	blocks := parseBlocks(append(bogonFile.IPv4, bogonFile.IPv6...))
+	if len(blocks) == 0 {
+		return common.NewServiceError("保留地址段数据文件为空: " + path)
+	}
+
+	bogonRWLock.Lock()
+	bogonBlocks = blocks
+	bogonModTime = info.ModTime()
+	bogonRWLock.Unlock()
+
+	log.Info(fmt.Sprintf("保留地址段数据加载完成, 共 %d 个 IPv4 段, %d 个 IPv6 段", len(bogonFile.IPv4), len(bogonFile.IPv6)))
+	return nil
+}
+
+// 返回 IP 所在的保留地址段, 不在保留地址段时为 nil
+func matchBogon(ip net.IP) *net.IPNet {
+	bogonRWLock.RLock()
+	defer bogonRWLock.RUnlock()
+	for _, block := range bogonBlocks {
+		if block.Contains(ip) {
+			return block
+		}
+	}
+	return nil
+}
+
+// 是否为已知污染 IP
+func isBogusIP(ip net.IP) bool {
+	for _, bogus := range models.BogusIPs {
+		if ip.Equal(net.ParseIP(bogus)) {
+			return true
+		}
+	}
+	return false
+}
+
+// 为 A / AAAA 记录打劫持评分
+// 地址本身的特征对每一层都检查; 与可信解析器对比只在顶层做, CNAME 子查询的结果已包含在顶层应答中
+func analyzeHijack(domain string, qtype uint16, in *dns.Msg, records []models.DNSRecord, r *models.Resolver, depth int) {
+	var baseline baselineAnswer
+	if depth == 0 && hijackBaseline != nil && hijackBaseline.URI != r.URI {
+		baseline = queryBaseline(domain, qtype)
+	}
+	threshold := env.GetServerConfig().Collector.Dns.HijackThreshold
+	if threshold <= 0 {
+		threshold = 50
+	}
+
+	for i := range records {
+		rec := &records[i]
+		if rec.Type != dns.TypeToString[qtype] || (qtype != dns.TypeA && qtype != dns.TypeAAAA) {
+			continue
+		}
+		ip := net.ParseIP(rec.Value)
+		if ip == nil {
+			continue
+		}
+		score := 0
+		reasons := []string{}
+		add := func(points int, format string, args ...any) {
+			score += points
+			reasons = append(reasons, fmt.Sprintf(format, args...))
+		}
+
+		if isBogusIP(ip) {
+			add(models.HijackScorePollution, "known pollution ip %s", rec.Value)
+		}
+		if operator, ok := models.SinkholeIPs[ip.String()]; ok {
+			add(models.HijackScoreSinkhole, "sinkhole ip %s (%s)", rec.Value, operator)
+		}
+		if block := matchBogon(ip); block != nil {
+			add(models.HijackScoreBogon, "bogon ip %s in %s", rec.Value, block.String())
+		}
+		if in.Rcode == dns.RcodeNameError {
+			add(models.HijackScoreNXDomain, "answer with rcode NXDOMAIN")
+		}
+
+		if baseline.ok {
+			geo := lookupGeoASN(ip)
+			_, sameIP := baseline.ips[rec.Value]
+			_, sameASN := baseline.asns[geo.ASNumber]
+			switch {
+			case baseline.nxdomain:
+				add(models.HijackScoreBaselineNX, "trusted resolver %s returns NXDOMAIN", hijackBaseline.Name)
+			case sameIP:
+			case len(baseline.ips) == 0:
+				add(models.HijackScoreBaselineASN, "trusted resolver %s returns no %s record", hijackBaseline.Name, rec.Type)
+			case sameASN && geo.ASNumber != 0:
+				add(models.HijackScoreBaselineOnly, "ip %s not in trusted answer but same %s", rec.Value, geo.ASN)
+			default:
+				add(models.HijackScoreBaselineASN, "ip %s (%s) not in trusted answer", rec.Value, orDefault(geo.ASN, "unknown asn"))
+				// 污染常见的 ASN 且可信解析器没有返回
+				if org, ok := models.PollutionASNs[geo.ASNumber]; ok {
+					add(models.HijackScoreImpossible, "impossible asn %s for %s", org, rec.Value)
+				}
+			}
+		}
+
+		rec.HijackScore = min(score, models.HijackScoreMax)
+		rec.HijackReason = reasons
+		rec.Hijacked = rec.HijackScore >= threshold
+	}
+}
+
+// 向可信解析器查询同一个名称
+func queryBaseline(domain string, qtype uint16) baselineAnswer {
+	res := baselineAnswer{ips: make(map[string]struct{}), asns: make(map[uint]struct{})}
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(domain), qtype)
+	in, _, err := exchange(m, hijackBaseline)
+	if err != nil {
+		log.Error(domain+" 可信解析器查询失败: ", err)
+		return res
+	}
+	switch in.Rcode {
+	case dns.RcodeSuccess:
+	case dns.RcodeNameError:
+		res.nxdomain = true
+	default:
+		// SERVFAIL 等无法作为对比依据
+		return res
+	}
+	res.ok = true
+	for _, rr := range in.Answer {
+		var ip net.IP
+		switch v := rr.(type) {
+		case *dns.A:
+			ip = v.A
+		case *dns.AAAA:
+			ip = v.AAAA
+		default:
+			continue
+		}
+		res.ips[ip.String()] = struct{}{}
+		if geo := lookupGeoASN(ip); geo.ASNumber != 0 {
+			res.asns[geo.ASNumber] = struct{}{}
+		}
+	}
+	return res
+}
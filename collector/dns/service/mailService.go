@@ -0,0 +1,564 @@
+package service
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/dns/models"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"github.com/miekg/dns"
+)
+
+// MTA-STS 策略文件大小上限 (RFC 8461 建议 64KB)
+const mtaSTSPolicyLimit = 64 << 10
+
+// MTA-STS 策略不允许跳转
+var mtaSTSClient = &http.Client{
+	Timeout: 10 * time.Second,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// ============== DNS解析 - 邮件安全 ==============
+
+// 检查域名的 SPF / DMARC / DKIM / MTA-STS / TLS-RPT 配置
+func checkMailSecurity(domain string) models.MailSecurity {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	res := models.MailSecurity{Domain: domain, MX: []string{}, DKIM: []models.DKIMResult{}, Findings: []models.Finding{}, Skipped: []string{}}
+	addFinding := func(check string, severity string, format string, args ...any) {
+		res.Findings = append(res.Findings, models.Finding{Check: check, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	mx, mxOK := lookupMX(domain)
+	res.MX = mx
+	sendsMail := len(res.MX) > 0
+	// 不知道是否收发邮件时, SPF / DKIM 的严重程度和 MTA-STS 的 MX 覆盖都无法判断
+	if !mxOK {
+		skipMailCheck(&res, models.MailCheckSPF, models.MailCheckDKIM, models.MailCheckMTASTS)
+	}
+
+	checkSPF(domain, &res, sendsMail, addFinding)
+	checkDMARC(domain, &res, addFinding)
+	checkDKIM(domain, &res, sendsMail, addFinding)
+	checkMTASTS(domain, &res, addFinding)
+
+	// TLS-RPT
+	tlsrpt, ok := queryTXT("_smtp._tls." + domain)
+	if !ok || mailCheckSkipped(&res, models.MailCheckMTASTS) {
+		skipMailCheck(&res, models.MailCheckTLSRPT)
+	}
+	for _, txt := range tlsrpt {
+		if !strings.HasPrefix(strings.ToLower(txt), "v=tlsrptv1") {
+			continue
+		}
+		res.TLSRPT = &models.TLSRPTResult{Record: txt, Rua: []string{}}
+		tags := parseTagList(txt)
+		for _, uri := range strings.Split(tags["rua"], ",") {
+			if uri = strings.TrimSpace(uri); uri != "" {
+				res.TLSRPT.Rua = append(res.TLSRPT.Rua, uri)
+			}
+		}
+		if len(res.TLSRPT.Rua) == 0 {
+			addFinding(models.MailCheckTLSRPT, models.SeverityLow, "TLS-RPT record has no rua")
+		}
+		break
+	}
+	if res.TLSRPT == nil && res.MTASTS != nil {
+		addFinding(models.MailCheckTLSRPT, models.SeverityInfo, "MTA-STS is published without TLS-RPT reporting")
+	}
+	return res
+}
+
+// 标记检查项未能完成
+func skipMailCheck(res *models.MailSecurity, checks ...string) {
+	for _, check := range checks {
+		if !mailCheckSkipped(res, check) {
+			res.Skipped = append(res.Skipped, check)
+		}
+	}
+}
+
+func mailCheckSkipped(res *models.MailSecurity, check string) bool {
+	for _, skipped := range res.Skipped {
+		if skipped == check {
+			return true
+		}
+	}
+	return false
+}
+
+// SPF 记录及递归查询数
+func checkSPF(domain string, res *models.MailSecurity, sendsMail bool, addFinding func(string, string, string, ...any)) {
+	txts, ok := queryTXT(domain)
+	if !ok {
+		skipMailCheck(res, models.MailCheckSPF)
+		return
+	}
+	var records []string
+	for _, txt := range txts {
+		if isSPF(txt) {
+			records = append(records, txt)
+		}
+	}
+	switch {
+	case len(records) == 0 && sendsMail:
+		addFinding(models.MailCheckSPF, models.SeverityHigh, "no SPF record")
+		return
+	case len(records) == 0:
+		addFinding(models.MailCheckSPF, models.SeverityMedium, "no SPF record, domains without mail should publish \"v=spf1 -all\"")
+		return
+	case len(records) > 1:
+		addFinding(models.MailCheckSPF, models.SeverityHigh, "multiple SPF records (permerror)")
+	}
+
+	spf := &models.SPFResult{Record: records[0], Mechanisms: []string{}, Includes: []string{}}
+	res.SPF = spf
+	walker := &spfWalker{seen: map[string]bool{domain: true}, result: spf}
+	walker.walk(records[0], domain, 0, true)
+	if walker.failed {
+		skipMailCheck(res, models.MailCheckSPF)
+	}
+	for _, msg := range walker.errors {
+		addFinding(models.MailCheckSPF, models.SeverityHigh, "%s", msg)
+	}
+
+	if spf.Lookups > models.SPFLookupLimit {
+		addFinding(models.MailCheckSPF, models.SeverityHigh, "SPF requires %d DNS lookups, limit is %d (permerror)", spf.Lookups, models.SPFLookupLimit)
+	} else if spf.Lookups >= models.SPFLookupLimit-1 {
+		addFinding(models.MailCheckSPF, models.SeverityLow, "SPF requires %d DNS lookups, close to the limit of %d", spf.Lookups, models.SPFLookupLimit)
+	}
+	if spf.VoidLookups > models.SPFVoidLookupLimit {
+		addFinding(models.MailCheckSPF, models.SeverityMedium, "SPF has %d void lookups, limit is %d", spf.VoidLookups, models.SPFVoidLookupLimit)
+	}
+	switch spf.All {
+	case "+":
+		addFinding(models.MailCheckSPF, models.SeverityCritical, "SPF ends with +all, any host may send mail")
+	case "?":
+		addFinding(models.MailCheckSPF, models.SeverityMedium, "SPF ends with ?all (neutral)")
+	case "":
+		if !walker.redirected {
+			addFinding(models.MailCheckSPF, models.SeverityMedium, "SPF has no all mechanism")
+		}
+	}
+}
+
+// 递归展开 SPF, 统计查询数
+type spfWalker struct {
+	result     *models.SPFResult
+	seen       map[string]bool
+	errors     []string
+	redirected bool
+	failed     bool // 展开过程中有查询失败, 查询数和错误不可信
+}
+
+func (w *spfWalker) walk(record string, domain string, depth int, top bool) {
+	var redirect string
+	hasAll := false
+	for _, term := range strings.Fields(record)[1:] {
+		lower := strings.ToLower(term)
+		if top {
+			w.result.Mechanisms = append(w.result.Mechanisms, term)
+		}
+
+		// 修饰符
+		if strings.HasPrefix(lower, "redirect=") {
+			redirect = term[len("redirect="):]
+			continue
+		}
+		if strings.Contains(lower, "=") {
+			continue
+		}
+
+		qualifier := "+"
+		if strings.ContainsAny(lower[:1], "+-~?") {
+			qualifier, lower = lower[:1], lower[1:]
+		}
+		name, arg, _ := strings.Cut(lower, ":")
+		name, _, _ = strings.Cut(name, "/")
+		arg, _, _ = strings.Cut(arg, "/")
+
+		switch name {
+		case "all":
+			hasAll = true
+			if top {
+				w.result.All = qualifier
+			}
+		case "include":
+			w.result.Lookups++
+			w.follow(arg, depth, "include")
+		case "a":
+			w.result.Lookups++
+			if !strings.Contains(arg, "%") && len(resolveHostAll(orDefault(arg, domain))) == 0 {
+				w.result.VoidLookups++
+			}
+		case "mx":
+			w.result.Lookups++
+			if !strings.Contains(arg, "%") {
+				if hosts, ok := lookupMX(orDefault(arg, domain)); !ok {
+					w.failed = true
+				} else if len(hosts) == 0 {
+					w.result.VoidLookups++
+				}
+			}
+		case "ptr":
+			w.result.Lookups++
+			if top {
+				w.errors = append(w.errors, "SPF uses the deprecated ptr mechanism")
+			}
+		case "exists":
+			w.result.Lookups++
+		case "ip4", "ip6":
+		default:
+			w.errors = append(w.errors, "unknown SPF mechanism "+term)
+		}
+	}
+
+	// 有 all 时 redirect 被忽略
+	if redirect != "" && !hasAll {
+		w.result.Lookups++
+		if top {
+			w.redirected = true
+		}
+		w.follow(redirect, depth, "redirect")
+	}
+}
+
+// 展开 include / redirect 指向的域名
+func (w *spfWalker) follow(target string, depth int, kind string) {
+	target = strings.TrimSuffix(strings.ToLower(target), ".")
+	if target == "" || strings.Contains(target, "%") {
+		return
+	}
+	// 当前展开路径上已出现过才是真正的循环, 嵌套过深单独报告
+	if w.seen[target] {
+		w.errors = append(w.errors, fmt.Sprintf("SPF %s loop at %s", kind, target))
+		return
+	}
+	if depth >= models.SPFLookupLimit {
+		w.errors = append(w.errors, fmt.Sprintf("SPF %s depth limit of %d exceeded at %s", kind, models.SPFLookupLimit, target))
+		return
+	}
+	// seen 只记录当前展开路径, 不同分支重复 include 同一域名时按实际解析重复计数
+	w.seen[target] = true
+	defer delete(w.seen, target)
+	w.result.Includes = append(w.result.Includes, target)
+
+	txts, ok := queryTXT(target)
+	if !ok {
+		w.failed = true
+		return
+	}
+	for _, txt := range txts {
+		if isSPF(txt) {
+			w.walk(txt, target, depth+1, false)
+			return
+		}
+	}
+	w.result.VoidLookups++
+	w.errors = append(w.errors, fmt.Sprintf("SPF %s target %s has no SPF record (permerror)", kind, target))
+}
+
+// DMARC 策略
+func checkDMARC(domain string, res *models.MailSecurity, addFinding func(string, string, string, ...any)) {
+	txts, ok := queryTXT("_dmarc." + domain)
+	if !ok {
+		skipMailCheck(res, models.MailCheckDMARC)
+		return
+	}
+	var record string
+	for _, txt := range txts {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(txt)), "v=dmarc1") {
+			if record != "" {
+				addFinding(models.MailCheckDMARC, models.SeverityHigh, "multiple DMARC records, receivers will ignore all of them")
+			}
+			record = txt
+		}
+	}
+	if record == "" {
+		addFinding(models.MailCheckDMARC, models.SeverityHigh, "no DMARC record")
+		return
+	}
+
+	tags := parseTagList(record)
+	dmarc := &models.DMARCResult{
+		Record:          record,
+		Policy:          strings.ToLower(tags["p"]),
+		SubdomainPolicy: strings.ToLower(tags["sp"]),
+		Percent:         100,
+		Rua:             splitURIs(tags["rua"]),
+		Ruf:             splitURIs(tags["ruf"]),
+		ADKIM:           orDefault(strings.ToLower(tags["adkim"]), "r"),
+		ASPF:            orDefault(strings.ToLower(tags["aspf"]), "r"),
+	}
+	if pct, err := strconv.Atoi(tags["pct"]); err == nil {
+		dmarc.Percent = pct
+	}
+	res.DMARC = dmarc
+
+	switch dmarc.Policy {
+	case "reject", "quarantine":
+	case "none":
+		addFinding(models.MailCheckDMARC, models.SeverityMedium, "DMARC policy is p=none, spoofed mail is still delivered")
+	default:
+		addFinding(models.MailCheckDMARC, models.SeverityHigh, "DMARC record has invalid policy %q", dmarc.Policy)
+	}
+	if dmarc.SubdomainPolicy == "none" && dmarc.Policy != "none" {
+		addFinding(models.MailCheckDMARC, models.SeverityLow, "DMARC subdomain policy is sp=none")
+	}
+	if dmarc.Percent < 100 {
+		addFinding(models.MailCheckDMARC, models.SeverityLow, "DMARC policy only applies to %d%% of mail", dmarc.Percent)
+	}
+	if len(dmarc.Rua) == 0 {
+		addFinding(models.MailCheckDMARC, models.SeverityInfo, "DMARC has no aggregate report address (rua)")
+	}
+}
+
+// 尝试常见 selector 的 DKIM 密钥
+func checkDKIM(domain string, res *models.MailSecurity, sendsMail bool, addFinding func(string, string, string, ...any)) {
+	selectors := env.GetServerConfig().Collector.Dns.DkimSelectors
+	if len(selectors) == 0 {
+		selectors = models.DefaultDKIMSelectors
+	}
+	for _, selector := range selectors {
+		txts, ok := queryTXT(selector + "._domainkey." + domain)
+		if !ok {
+			skipMailCheck(res, models.MailCheckDKIM)
+			continue
+		}
+		for _, txt := range txts {
+			tags := parseTagList(txt)
+			p, ok := tags["p"]
+			if !ok {
+				continue
+			}
+			key := models.DKIMResult{
+				Selector: selector,
+				KeyType:  orDefault(strings.ToLower(tags["k"]), "rsa"),
+				Revoked:  p == "",
+				Testing:  strings.Contains(strings.ToLower(tags["t"]), "y"),
+			}
+			if key.KeyType == "rsa" && p != "" {
+				key.KeyBits = rsaKeyBits(p)
+				switch {
+				case key.KeyBits == 0:
+					addFinding(models.MailCheckDKIM, models.SeverityMedium, "DKIM selector %s has an unparsable key", selector)
+				case key.KeyBits < 1024:
+					addFinding(models.MailCheckDKIM, models.SeverityHigh, "DKIM selector %s uses a %d-bit RSA key", selector, key.KeyBits)
+				case key.KeyBits < 2048:
+					addFinding(models.MailCheckDKIM, models.SeverityLow, "DKIM selector %s uses a %d-bit RSA key", selector, key.KeyBits)
+				}
+			}
+			if key.Testing {
+				addFinding(models.MailCheckDKIM, models.SeverityInfo, "DKIM selector %s is in testing mode", selector)
+			}
+			res.DKIM = append(res.DKIM, key)
+			break
+		}
+	}
+	if len(res.DKIM) == 0 && sendsMail {
+		addFinding(models.MailCheckDKIM, models.SeverityInfo, "no DKIM key found under common selectors")
+	}
+}
+
+// MTA-STS 记录和策略文件
+func checkMTASTS(domain string, res *models.MailSecurity, addFinding func(string, string, string, ...any)) {
+	txts, ok := queryTXT("_mta-sts." + domain)
+	if !ok {
+		skipMailCheck(res, models.MailCheckMTASTS)
+		return
+	}
+	var record string
+	for _, txt := range txts {
+		if strings.HasPrefix(strings.ToLower(txt), "v=stsv1") {
+			record = txt
+			break
+		}
+	}
+	if record == "" {
+		return
+	}
+
+	sts := &models.MTASTSResult{Record: record, ID: parseTagList(record)["id"], MX: []string{}}
+	res.MTASTS = sts
+	if sts.ID == "" {
+		addFinding(models.MailCheckMTASTS, models.SeverityMedium, "MTA-STS record has no id")
+	}
+
+	resp, err := mtaSTSClient.Get("https://mta-sts." + domain + "/.well-known/mta-sts.txt")
+	if err != nil {
+		sts.Error = err.Error()
+		addFinding(models.MailCheckMTASTS, models.SeverityHigh, "MTA-STS policy fetch failed: %s", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		sts.Error = fmt.Sprintf("status %d", resp.StatusCode)
+		addFinding(models.MailCheckMTASTS, models.SeverityHigh, "MTA-STS policy returned HTTP %d", resp.StatusCode)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, mtaSTSPolicyLimit))
+	if err != nil {
+		sts.Error = err.Error()
+		addFinding(models.MailCheckMTASTS, models.SeverityHigh, "MTA-STS policy read failed: %s", err.Error())
+		return
+	}
+
+	// key: value 每行一项
+	version := ""
+	for _, line := range strings.Split(string(body), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "version":
+			version = value
+		case "mode":
+			sts.Mode = strings.ToLower(value)
+		case "mx":
+			sts.MX = append(sts.MX, strings.ToLower(value))
+		case "max_age":
+			sts.MaxAge, _ = strconv.Atoi(value)
+		}
+	}
+	if version != "STSv1" || sts.Mode == "" || sts.MaxAge == 0 {
+		sts.Error = "invalid policy"
+		addFinding(models.MailCheckMTASTS, models.SeverityHigh, "MTA-STS policy is invalid (version %q, mode %q, max_age %d)", version, sts.Mode, sts.MaxAge)
+		return
+	}
+	sts.PolicyOK = true
+
+	switch sts.Mode {
+	case "enforce":
+	case "testing":
+		addFinding(models.MailCheckMTASTS, models.SeverityInfo, "MTA-STS is in testing mode")
+	case "none":
+		addFinding(models.MailCheckMTASTS, models.SeverityLow, "MTA-STS mode is none")
+	}
+	// MX 必须被策略覆盖
+	for _, mx := range res.MX {
+		if !matchMTASTSPattern(sts.MX, mx) {
+			addFinding(models.MailCheckMTASTS, models.SeverityHigh, "MX %s is not covered by the MTA-STS policy", mx)
+		}
+	}
+}
+
+// MTA-STS mx 模式匹配, 支持开头的 *. 通配一级
+func matchMTASTSPattern(patterns []string, host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, p := range patterns {
+		p = strings.TrimSuffix(p, ".")
+		if p == host {
+			return true
+		}
+		if strings.HasPrefix(p, "*.") {
+			if _, rest, ok := strings.Cut(host, "."); ok && rest == p[2:] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 查询 TXT, 同一条记录的多个字符串直接拼接
+// 查询失败或应答码不是 NOERROR / NXDOMAIN 时 ok 为 false, 不能当作没有记录
+func queryTXT(name string) ([]string, bool) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(name), dns.TypeTXT)
+	m.SetEdns0(4096, false)
+	in, _, err := exchange(m, resolver)
+	if err != nil || (in.Rcode != dns.RcodeSuccess && in.Rcode != dns.RcodeNameError) {
+		return nil, false
+	}
+	var res []string
+	for _, rr := range in.Answer {
+		if txt, ok := rr.(*dns.TXT); ok {
+			res = append(res, strings.Join(txt.Txt, ""))
+		}
+	}
+	return res, true
+}
+
+// 查询 MX 主机, 按主机名排序, 查询失败时 ok 为 false
+func lookupMX(domain string) ([]string, bool) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(domain), dns.TypeMX)
+	in, _, err := exchange(m, resolver)
+	if err != nil || (in.Rcode != dns.RcodeSuccess && in.Rcode != dns.RcodeNameError) {
+		return nil, false
+	}
+	var res []string
+	for _, rr := range in.Answer {
+		// 空 MX (RFC 7505) 表示不收邮件
+		if mx, ok := rr.(*dns.MX); ok && mx.Mx != "." {
+			res = append(res, strings.TrimSuffix(strings.ToLower(mx.Mx), "."))
+		}
+	}
+	sort.Strings(res)
+	return res, true
+}
+
+func isSPF(txt string) bool {
+	lower := strings.ToLower(strings.TrimSpace(txt))
+	return lower == "v=spf1" || strings.HasPrefix(lower, "v=spf1 ")
+}
+
+// 解析 tag=value; 格式 (DMARC / DKIM / MTA-STS / TLS-RPT)
+func parseTagList(record string) map[string]string {
+	tags := make(map[string]string)
+	for _, part := range strings.Split(record, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		tags[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return tags
+}
+
+// 拆分逗号分隔的报告地址
+func splitURIs(value string) []string {
+	res := []string{}
+	for _, uri := range strings.Split(value, ",") {
+		if uri = strings.TrimSpace(uri); uri != "" {
+			res = append(res, uri)
+		}
+	}
+	return res
+}
+
+// DKIM RSA 公钥长度, 解析失败返回 0
+func rsaKeyBits(p string) int {
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(p), ""))
+	if err != nil {
+		return 0
+	}
+	if pub, err := x509.ParsePKIXPublicKey(der); err == nil {
+		if key, ok := pub.(*rsa.PublicKey); ok {
+			return key.N.BitLen()
+		}
+		return 0
+	}
+	// 部分服务商发布的是 PKCS#1 格式
+	if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return key.N.BitLen()
+	}
+	return 0
+}
+
+func orDefault(value string, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/dns/models"
+	"github.com/GoFurry/gofurry-nav-collector/common/log"
+	cs "github.com/GoFurry/gofurry-nav-collector/common/service"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"github.com/miekg/dns"
+)
+
+// ============== DNS解析 - 多解析器对比 ==============
+
+// 查找域名所在区域的 NS, 子域名没有 NS 时逐级向上查找
+func findZoneNameservers(domain string) (string, []string) {
+	labels := dns.SplitDomainName(domain)
+	for i := 0; i < len(labels)-1; i++ {
+		zone := dns.Fqdn(strings.Join(labels[i:], "."))
+		if nameservers := lookupNS(zone); len(nameservers) > 0 {
+			return zone, nameservers
+		}
+	}
+	return "", nil
+}
+
+// 通过默认解析器查询区域的 NS
+func lookupNS(zone string) []string {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(zone), dns.TypeNS)
+	in, _, err := exchange(m, resolver)
+	if err != nil || in == nil {
+		return nil
+	}
+	var nameservers []string
+	for _, rr := range in.Answer {
+		if ns, ok := rr.(*dns.NS); ok && strings.EqualFold(ns.Hdr.Name, dns.Fqdn(zone)) {
+			nameservers = append(nameservers, ns.Ns)
+		}
+	}
+	sort.Strings(nameservers)
+	return nameservers
+}
+
+// 解析主机名的第一个 IPv4 地址
+func resolveHost(host string) string {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(host), dns.TypeA)
+	in, _, err := exchange(m, resolver)
+	if err != nil || in == nil {
+		return ""
+	}
+	for _, rr := range in.Answer {
+		if a, ok := rr.(*dns.A); ok {
+			return a.A.String()
+		}
+	}
+	return ""
+}
+
+// 域名所在区域的权威服务器, 取第一个可解析的 NS
+func authoritativeResolver(domain string) *models.Resolver {
+	_, nameservers := findZoneNameservers(domain)
+	for _, ns := range nameservers {
+		if ip := resolveHost(ns); ip != "" {
+			r, _ := parseResolver(env.ResolverConfig{Name: strings.TrimSuffix(ns, "."), Address: net.JoinHostPort(ip, "53")})
+			return r
+		}
+	}
+	return nil
+}
+
+// 多解析器并行查询 A / AAAA, 标记不一致和疑似污染的应答
+func compareResolvers(domain string) models.ResolverComparison {
+	type target struct {
+		resolver *models.Resolver
+		group    string
+	}
+
+	var targets []target
+	for _, conf := range env.GetServerConfig().Collector.Dns.Resolvers {
+		r, err := parseResolver(conf)
+		if err != nil {
+			log.Error("解析器 ", conf.Name, " 配置错误: ", err.GetMsg())
+			continue
+		}
+		targets = append(targets, target{resolver: r, group: conf.Group})
+	}
+	if len(targets) == 0 {
+		targets = append(targets, target{resolver: resolver, group: models.ResolverGroupForeign})
+	}
+	// 权威服务器
+	if env.GetServerConfig().Collector.Dns.CompareAuthoritative {
+		if r := authoritativeResolver(domain); r != nil {
+			targets = append(targets, target{resolver: r, group: models.ResolverGroupAuthoritative})
+		}
+	}
+
+	res := models.ResolverComparison{Answers: []models.ResolverAnswer{}, Consistent: true, Divergent: []string{}, Polluted: []string{}}
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		answers := make([]models.ResolverAnswer, len(targets))
+		var queryWG sync.WaitGroup
+		for i, t := range targets {
+			queryWG.Add(1)
+			go func(i int, t target) {
+				defer queryWG.Done()
+				answers[i] = queryResolver(domain, qtype, t.resolver, t.group)
+			}(i, t)
+		}
+		queryWG.Wait()
+
+		analyzeAnswers(answers)
+		for _, a := range answers {
+			if a.Error != "" {
+				continue
+			}
+			if a.Divergent {
+				res.Consistent = false
+				res.Divergent = append(res.Divergent, a.Resolver+"/"+a.Type)
+			}
+			if a.Polluted {
+				res.Polluted = append(res.Polluted, a.Resolver+"/"+a.Type)
+			}
+		}
+		res.Answers = append(res.Answers, answers...)
+	}
+	// IP 集合不一致也视为不一致 (CDN 就近调度时 ASN 可能一致)
+	keys := make(map[string]struct{})
+	for _, a := range res.Answers {
+		if a.Error == "" {
+			keys[a.Type+"|"+strings.Join(a.Answers, ",")] = struct{}{}
+		}
+	}
+	if len(keys) > 2 || (len(keys) == 2 && !hasBothTypes(keys)) {
+		res.Consistent = false
+	}
+	return res
+}
+
+// 判断 key 集合是否恰好是 A 和 AAAA 各一个
+func hasBothTypes(keys map[string]struct{}) bool {
+	var a, aaaa bool
+	for k := range keys {
+		a = a || strings.HasPrefix(k, "A|")
+		aaaa = aaaa || strings.HasPrefix(k, "AAAA|")
+	}
+	return a && aaaa
+}
+
+// 向单个解析器查询
+func queryResolver(domain string, qtype uint16, r *models.Resolver, group string) models.ResolverAnswer {
+	answer := models.ResolverAnswer{
+		Resolver:  r.Name,
+		Address:   r.URI,
+		Transport: r.Transport,
+		Group:     group,
+		Type:      dns.TypeToString[qtype],
+		Answers:   []string{},
+		ASNs:      []string{},
+	}
+
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(domain), qtype)
+	// 权威服务器不需要递归
+	m.RecursionDesired = group != models.ResolverGroupAuthoritative
+	in, rtt, err := exchange(m, r)
+	answer.RTT = rtt
+	if err != nil {
+		answer.Error = err.Error()
+		return answer
+	}
+
+	asnSet := make(map[string]struct{})
+	for _, rr := range in.Answer {
+		var ip net.IP
+		switch v := rr.(type) {
+		case *dns.A:
+			ip = v.A
+		case *dns.AAAA:
+			ip = v.AAAA
+		default:
+			continue
+		}
+		answer.Answers = append(answer.Answers, ip.String())
+		if geo := cs.LookupGeoIP(ip); geo.ASNumber != 0 {
+			asnSet[geo.ASN] = struct{}{}
+		}
+	}
+	for asn := range asnSet {
+		answer.ASNs = append(answer.ASNs, asn)
+	}
+	sort.Strings(answer.Answers)
+	sort.Strings(answer.ASNs)
+	return answer
+}
+
+// 与多数结果对比, 并检查污染特征
+func analyzeAnswers(answers []models.ResolverAnswer) {
+	// 统计多数结果
+	ipVotes := make(map[string]int)
+	asnVotes := make(map[string]int)
+	for _, a := range answers {
+		if a.Error != "" || len(a.Answers) == 0 {
+			continue
+		}
+		ipVotes[strings.Join(a.Answers, ",")]++
+		asnVotes[strings.Join(a.ASNs, ",")]++
+	}
+	majorityIP, majorityASN := topVote(ipVotes), topVote(asnVotes)
+
+	for i := range answers {
+		a := &answers[i]
+		if a.Error != "" || len(a.Answers) == 0 {
+			continue
+		}
+		if strings.Join(a.Answers, ",") != majorityIP && strings.Join(a.ASNs, ",") != majorityASN {
+			a.Divergent = true
+		}
+
+		for _, value := range a.Answers {
+			ip := net.ParseIP(value)
+			// 已知污染 IP
+			if isBogusIP(ip) {
+				a.PollutedReasons = append(a.PollutedReasons, "known bogus ip "+value)
+			}
+			// 私网 / 保留地址
+			if matchBogon(ip) != nil {
+				a.PollutedReasons = append(a.PollutedReasons, "reserved ip "+value)
+			}
+			// 不可能的 ASN: 命中污染常见 ASN 且其他解析器都没有返回该 ASN
+			geo := cs.LookupGeoIP(ip)
+			if org, ok := models.PollutionASNs[geo.ASNumber]; ok && !asnSeenElsewhere(answers, i, geo.ASN) {
+				a.PollutedReasons = append(a.PollutedReasons, "impossible asn "+org+" for "+value)
+			}
+		}
+		a.Polluted = len(a.PollutedReasons) > 0
+	}
+}
+
+// 其他解析器是否返回过该 ASN
+func asnSeenElsewhere(answers []models.ResolverAnswer, self int, asn string) bool {
+	for i, a := range answers {
+		if i == self {
+			continue
+		}
+		for _, v := range a.ASNs {
+			if v == asn {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 得票最多的结果, 同票时取字典序最小的保证稳定
+func topVote(votes map[string]int) string {
+	best, bestCount := "", 0
+	for k, v := range votes {
+		if v > bestCount || (v == bestCount && k < best) {
+			best, bestCount = k, v
+		}
+	}
+	return best
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/dns/models"
+	"github.com/miekg/dns"
+	"golang.org/x/net/publicsuffix"
+)
+
+// 页面读取上限
+const takeoverBodyLimit = 256 << 10
+
+// 接管检查的 HTTP 客户端, 已释放的服务证书通常不匹配, 不校验证书
+var takeoverClient = &http.Client{
+	Timeout:   10 * time.Second,
+	Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+}
+
+// CNAME 指向
+type cnameLink struct {
+	owner  string
+	target string
+}
+
+// ============== DNS解析 - 子域名接管 ==============
+
+// 检查解析结果中的 CNAME 是否指向已释放、可被他人认领的资源
+// 站点自身查询、目标查询或页面请求失败时 complete 为 false, 结果不完整, 不应覆盖已有发现
+func checkTakeover(results map[string][]models.DNSRecord, responses map[string]models.DNSResponse) (findings []models.Finding, complete bool) {
+	findings = []models.Finding{}
+	// CNAME 通过 CNAME / A 查询得到, 这两类查询失败时可能漏掉 CNAME
+	complete = !anyTypeFailed(responses, dns.TypeCNAME, dns.TypeA)
+
+	links := collectCNAMEs(results)
+	owners := make(map[string]struct{}, len(links))
+	for _, link := range links {
+		owners[link.owner] = struct{}{}
+	}
+	pages := make(map[string]string) // CNAME 所有者的页面, 需要时才请求, 每个名称只请求一次
+	for _, link := range links {
+		// 链路中间的目标本身也是 CNAME, 只检查链路末端, 避免同一个悬空目标按不同所有者重复报告
+		if _, ok := owners[link.target]; ok {
+			continue
+		}
+		sig := matchTakeoverSignature(link.target)
+		rcode, err := lookupRcode(link.target, dns.TypeA)
+		if err != nil || (rcode != dns.RcodeSuccess && rcode != dns.RcodeNameError) {
+			complete = false
+			continue
+		}
+
+		if rcode == dns.RcodeNameError {
+			// 目标所在的可注册域名本身未注册, 任何人都能注册
+			if registrable, suffixErr := publicsuffix.EffectiveTLDPlusOne(link.target); suffixErr == nil {
+				soaRcode, soaErr := lookupRcode(registrable, dns.TypeSOA)
+				if soaErr != nil {
+					complete = false
+				} else if soaRcode == dns.RcodeNameError {
+					findings = append(findings, models.Finding{
+						Check: models.FindingCheckTakeover, Severity: models.SeverityCritical, Target: link.target,
+						Message: fmt.Sprintf("%s CNAME %s: domain %s is not registered", link.owner, link.target, registrable),
+					})
+					continue
+				}
+			}
+			switch {
+			case sig != nil && sig.NXDomain:
+				findings = append(findings, models.Finding{
+					Check: models.FindingCheckTakeover, Severity: models.SeverityHigh, Target: link.target,
+					Message: fmt.Sprintf("%s CNAME %s: %s resource returns NXDOMAIN and can be claimed", link.owner, link.target, sig.Service),
+				})
+			case sig != nil:
+				findings = append(findings, models.Finding{
+					Check: models.FindingCheckTakeover, Severity: models.SeverityMedium, Target: link.target,
+					Message: fmt.Sprintf("%s CNAME %s: %s resource returns NXDOMAIN", link.owner, link.target, sig.Service),
+				})
+			default:
+				findings = append(findings, models.Finding{
+					Check: models.FindingCheckTakeover, Severity: models.SeverityMedium, Target: link.target,
+					Message: fmt.Sprintf("%s CNAME %s: dangling target returns NXDOMAIN", link.owner, link.target),
+				})
+			}
+			continue
+		}
+
+		// 目标仍能解析, 通过服务返回的页面判断资源是否已删除
+		if sig == nil || len(sig.Fingerprints) == 0 {
+			continue
+		}
+		// 以 CNAME 所有者的名称访问, 服务按 Host 找不到资源时才返回特征页面
+		page, ok := pages[link.owner]
+		if !ok {
+			var fetched bool
+			if page, fetched = fetchTakeoverPage(link.owner); !fetched {
+				complete = false
+			}
+			pages[link.owner] = page
+		}
+		for _, fp := range sig.Fingerprints {
+			if strings.Contains(page, fp) {
+				findings = append(findings, models.Finding{
+					Check: models.FindingCheckTakeover, Severity: models.SeverityHigh, Target: link.target,
+					Message: fmt.Sprintf("%s CNAME %s: %s page %q indicates an unclaimed resource", link.owner, link.target, sig.Service, fp),
+				})
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return models.SeverityRank[findings[i].Severity] > models.SeverityRank[findings[j].Severity]
+	})
+	return findings, complete
+}
+
+// 收集全部 CNAME, 包括递归查询产生的子记录
+func collectCNAMEs(results map[string][]models.DNSRecord) []cnameLink {
+	var links []cnameLink
+	seen := make(map[cnameLink]struct{})
+	var walk func(records []models.DNSRecord)
+	walk = func(records []models.DNSRecord) {
+		for _, rec := range records {
+			if rec.Type == "CNAME" {
+				link := cnameLink{
+					owner:  strings.TrimSuffix(strings.ToLower(rec.Name), "."),
+					target: strings.TrimSuffix(strings.ToLower(rec.Value), "."),
+				}
+				if _, ok := seen[link]; !ok {
+					seen[link] = struct{}{}
+					links = append(links, link)
+				}
+			}
+			walk(rec.Children)
+		}
+	}
+	keys := make([]string, 0, len(results))
+	for k := range results {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		walk(results[k])
+	}
+	return links
+}
+
+// 按 CNAME 后缀匹配服务
+func matchTakeoverSignature(target string) *models.TakeoverSignature {
+	for i, sig := range models.TakeoverSignatures {
+		for _, suffix := range sig.CNAMEs {
+			if target == suffix || strings.HasSuffix(target, "."+suffix) {
+				return &models.TakeoverSignatures[i]
+			}
+		}
+	}
+	return nil
+}
+
+// 查询应答码
+func lookupRcode(name string, qtype uint16) (int, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(name), qtype)
+	in, _, err := exchange(m, resolver)
+	if err != nil {
+		return 0, err
+	}
+	return in.Rcode, nil
+}
+
+// 请求名称的首页, http 失败时再试 https, 都失败时返回 false
+func fetchTakeoverPage(host string) (string, bool) {
+	for _, scheme := range []string{"http://", "https://"} {
+		resp, err := takeoverClient.Get(scheme + host + "/")
+		if err != nil {
+			continue
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, takeoverBodyLimit))
+		resp.Body.Close()
+		return string(body), true
+	}
+	return "", false
+}
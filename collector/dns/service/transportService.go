@@ -0,0 +1,228 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/dns/models"
+	"github.com/GoFurry/gofurry-nav-collector/common"
+	"github.com/GoFurry/gofurry-nav-collector/common/log"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"github.com/miekg/dns"
+)
+
+// 默认查询超时
+const defaultResolverTimeout = 5 * time.Second
+
+// DoH 客户端, 按 URI 和连接参数复用连接
+var dohClients sync.Map
+
+// 同一个 URI 的 SNI / 引导 IP / 超时不同时不能共用客户端
+type dohClientKey struct {
+	uri        string
+	serverName string
+	bootstrap  string
+	timeout    time.Duration
+}
+
+// ============== DNS解析 - 传输部分 ==============
+
+// 初始化默认解析器, 配置有误时回退到 8.8.8.8:53
+func initResolver() *models.Resolver {
+	conf := env.GetServerConfig().Collector.Dns
+	r, err := parseResolver(env.ResolverConfig{
+		Name:      "default",
+		Address:   conf.Resolver,
+		Timeout:   conf.ResolverTimeout,
+		SNI:       conf.ResolverSNI,
+		Bootstrap: conf.ResolverBootstrap,
+	})
+	if err != nil {
+		log.Error("默认解析器配置错误: ", err.GetMsg())
+		r, _ = parseResolver(env.ResolverConfig{Name: "default", Address: "8.8.8.8:53"})
+	}
+	return r
+}
+
+// 解析解析器 URI
+// 支持 udp://host[:53] tcp://host[:53] tls://host[:853] https://host/dns-query, 不带协议时按 udp 处理
+func parseResolver(conf env.ResolverConfig) (*models.Resolver, common.GFError) {
+	raw := strings.TrimSpace(conf.Address)
+	if raw == "" {
+		return nil, common.NewServiceError("解析器地址为空")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = models.TransportUDP + "://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, common.NewServiceError("解析器地址错误: " + err.Error())
+	}
+	host := u.Hostname()
+	if host == "" {
+		return nil, common.NewServiceError("解析器地址缺少主机: " + conf.Address)
+	}
+
+	r := &models.Resolver{
+		Name:       conf.Name,
+		URI:        conf.Address,
+		Transport:  strings.ToLower(u.Scheme),
+		Host:       host,
+		ServerName: conf.SNI,
+		Bootstrap:  conf.Bootstrap,
+		Timeout:    defaultResolverTimeout,
+	}
+	if conf.Timeout > 0 {
+		r.Timeout = time.Duration(conf.Timeout) * time.Second
+	}
+	if r.ServerName == "" {
+		r.ServerName = host
+	}
+	if r.Bootstrap != "" && net.ParseIP(r.Bootstrap) == nil {
+		return nil, common.NewServiceError("引导 IP 错误: " + r.Bootstrap)
+	}
+
+	port := u.Port()
+	switch r.Transport {
+	case models.TransportUDP, models.TransportTCP:
+		if port == "" {
+			port = "53"
+		}
+	case models.TransportTLS:
+		if port == "" {
+			port = "853"
+		}
+	case models.TransportHTTPS:
+		if u.Path == "" {
+			u.Path = "/dns-query"
+		}
+		r.Address = u.String()
+		return r, nil
+	default:
+		return nil, common.NewServiceError("不支持的解析器协议: " + u.Scheme)
+	}
+
+	// 有引导 IP 时直接连接, 不再解析解析器主机名
+	dialHost := host
+	if r.Bootstrap != "" {
+		dialHost = r.Bootstrap
+	}
+	r.Address = net.JoinHostPort(dialHost, port)
+	return r, nil
+}
+
+// 通过指定解析器执行一次查询, 返回应答和耗时
+func exchange(m *dns.Msg, r *models.Resolver) (*dns.Msg, time.Duration, error) {
+	in, rtt, _, err := exchangeWithFallback(m, r)
+	return in, rtt, err
+}
+
+// UDP 应答被截断时自动改用 TCP 重试, 第三个返回值表示是否发生了重试
+func exchangeWithFallback(m *dns.Msg, r *models.Resolver) (*dns.Msg, time.Duration, bool, error) {
+	in, rtt, err := exchangeOnce(m, r)
+	if err != nil || in == nil || !in.Truncated || r.Transport != models.TransportUDP {
+		return in, rtt, false, err
+	}
+
+	tcp := *r
+	tcp.Transport = models.TransportTCP
+	retry, retryRTT, retryErr := exchangeOnce(m, &tcp)
+	if retryErr != nil {
+		// TCP 不可用时保留截断的应答
+		log.Warn(fmt.Sprintf("%s TCP 重试失败: %v", r.Name, retryErr))
+		return in, rtt, false, nil
+	}
+	return retry, rtt + retryRTT, true, nil
+}
+
+// 按解析器的传输方式执行单次查询
+func exchangeOnce(m *dns.Msg, r *models.Resolver) (*dns.Msg, time.Duration, error) {
+	switch r.Transport {
+	case models.TransportHTTPS:
+		return exchangeHTTPS(m, r)
+	case models.TransportTLS:
+		c := &dns.Client{Net: "tcp-tls", Timeout: r.Timeout, TLSConfig: &tls.Config{ServerName: r.ServerName}}
+		return c.Exchange(m, r.Address)
+	case models.TransportTCP:
+		c := &dns.Client{Net: "tcp", Timeout: r.Timeout}
+		return c.Exchange(m, r.Address)
+	default:
+		c := &dns.Client{Net: "udp", Timeout: r.Timeout}
+		return c.Exchange(m, r.Address)
+	}
+}
+
+// DNS-over-HTTPS 查询 (RFC 8484, POST application/dns-message)
+func exchangeHTTPS(m *dns.Msg, r *models.Resolver) (*dns.Msg, time.Duration, error) {
+	// ID 置 0 便于 HTTP 缓存, 应答后再还原
+	query := m.Copy()
+	query.Id = 0
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req, err := http.NewRequest("POST", r.Address, bytes.NewReader(packed))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	start := time.Now()
+	resp, err := dohClient(r).Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, time.Since(start), fmt.Errorf("DoH 返回 %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, dns.MaxMsgSize))
+	rtt := time.Since(start)
+	if err != nil {
+		return nil, rtt, err
+	}
+
+	in := new(dns.Msg)
+	if err = in.Unpack(body); err != nil {
+		return nil, rtt, err
+	}
+	in.Id = m.Id
+	return in, rtt, nil
+}
+
+// 获取 DoH 客户端, 设置 SNI 和引导 IP
+func dohClient(r *models.Resolver) *http.Client {
+	key := dohClientKey{uri: r.URI, serverName: r.ServerName, bootstrap: r.Bootstrap, timeout: r.Timeout}
+	if c, ok := dohClients.Load(key); ok {
+		return c.(*http.Client)
+	}
+
+	dialer := &net.Dialer{Timeout: key.timeout}
+	transport := &http.Transport{
+		TLSClientConfig:   &tls.Config{ServerName: key.serverName},
+		ForceAttemptHTTP2: true,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if key.bootstrap != "" {
+				_, port, err := net.SplitHostPort(addr)
+				if err != nil {
+					return nil, err
+				}
+				addr = net.JoinHostPort(key.bootstrap, port)
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+	c, _ := dohClients.LoadOrStore(key, &http.Client{Transport: transport, Timeout: key.timeout})
+	return c.(*http.Client)
+}
@@ -26,6 +26,16 @@ func (dao httpDao) GetList() ([]models.GfnCollectorDomain, common.GFError) {
 	return res, nil
 }
 
+// 修正域名的 tls / prefix 配置
+func (dao httpDao) UpdateVariant(id int64, tls string, prefix *string) common.GFError {
+	db := dao.Gm.Table(models.TableNameGfnCollectorDomain).Where("id = ?", id).
+		Updates(map[string]any{"tls": tls, "prefix": prefix})
+	if err := db.Error; err != nil {
+		return common.NewDaoError(err.Error())
+	}
+	return nil
+}
+
 // 保留 count 条request历史记录
 func (dao httpDao) DeleteByNum(count string) (int64, common.GFError) {
 	sql := `
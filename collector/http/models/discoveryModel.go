@@ -0,0 +1,35 @@
+package models
+
+import "github.com/GoFurry/gofurry-nav-collector/common/models"
+
+// 单个访问入口 (scheme + host) 的探测结果
+type VariantModel struct {
+	Url          string   `json:"url"`          // 探测 url
+	Reachable    bool     `json:"reachable"`    // 是否有响应
+	StatusCode   int64    `json:"statusCode"`   // 最终状态码
+	FinalUrl     string   `json:"finalUrl"`     // 最终落地 url
+	Redirects    []string `json:"redirects"`    // 重定向链
+	UpgradeHTTPS bool     `json:"upgradeHttps"` // http 是否跳转到 https
+	ResponseTime int64    `json:"responseTime"` // 响应时间 ms
+	Error        string   `json:"error"`        // 失败原因
+}
+
+// 访问入口发现结果
+type DiscoveryModel struct {
+	Domain          string           `json:"domain"`          // 域名
+	Variants        []VariantModel   `json:"variants"`        // 各入口探测结果
+	HTTPUpgrade     bool             `json:"httpUpgrade"`     // http 是否全部正确跳转到 https
+	CurrentTLS      string           `json:"currentTls"`      // 当前 tls 配置
+	CurrentPrefix   string           `json:"currentPrefix"`   // 当前 prefix 配置
+	SuggestedTLS    string           `json:"suggestedTls"`    // 建议 tls 配置
+	SuggestedPrefix string           `json:"suggestedPrefix"` // 建议 prefix 配置
+	NeedCorrection  bool             `json:"needCorrection"`  // 当前配置是否需要修正
+	Applied         bool             `json:"applied"`         // 是否已自动修正
+	CheckTime       models.LocalTime `json:"checkTime"`       // 探测时间
+}
+
+// 探测的前缀
+var DiscoveryPrefixes = []string{"", "www."}
+
+// 探测的协议, 按优先级排列
+var DiscoverySchemes = []string{"https", "http"}
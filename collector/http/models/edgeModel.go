@@ -0,0 +1,173 @@
+package models
+
+// 识别出的 CDN / WAF
+type EdgeProvider struct {
+	Name     string   `json:"name"`     // 服务商名称
+	Category string   `json:"category"` // CDN / WAF
+	Evidence []string `json:"evidence"` // 命中依据
+}
+
+// CDN / WAF 识别规则
+// 节点 IP 段和 ASN 在服务商数据文件 (provider.yaml) 中维护, 与 DNS 采集共用, Name 与其中的服务商名称一致时合并命中依据
+type EdgeRule struct {
+	Name     string
+	Category string
+	Headers  map[string]string // 响应头名称 -> 正则 (空串表示存在即命中)
+	Cookies  []string          // cookie 名称正则
+}
+
+// 质询页 / 拦截页识别规则
+type ChallengeRule struct {
+	Name    string
+	Headers map[string]string // 响应头名称 -> 正则
+	Title   string            // 标题正则
+	Body    []string          // 页面内容正则
+}
+
+// 请求状态
+const (
+	HTTPStatusSuccess   = "success"   // 成功
+	HTTPStatusFailure   = "failure"   // 失败
+	HTTPStatusChallenge = "challenge" // 被质询页 / 拦截页挡住
+)
+
+// CDN / WAF 识别规则列表
+var EdgeRules = []EdgeRule{
+	{
+		Name: "Cloudflare", Category: "CDN",
+		Headers: map[string]string{"cf-ray": "", "cf-cache-status": "", "server": "^cloudflare"},
+		Cookies: []string{"^__cf_bm$", "^cf_clearance$", "^__cflb$", "^__cfruid$"},
+	},
+	{
+		Name: "Fastly", Category: "CDN",
+		// x-cache 逐层追加命中状态, 如 "HIT, MISS"
+		Headers: map[string]string{"x-served-by": "^cache-", "x-fastly-request-id": "", "via": "varnish|fastly", "x-cache": "^(?:HIT|MISS|PASS)(?:, (?:HIT|MISS|PASS))+$"},
+	},
+	{
+		Name: "Akamai", Category: "CDN",
+		// x-cache 如 TCP_MISS from a23-1-2-3.deploy.akamaitechnologies.com
+		Headers: map[string]string{"server": "^AkamaiGHost|^AkamaiNetStorage", "x-akamai-transformed": "", "akamai-grn": "", "akamai-cache-status": "", "x-cache": "^TCP_[A-Z_]+ from .*akamai"},
+		Cookies: []string{"^ak_bmsc$", "^bm_sz$", "^_abck$"},
+	},
+	{
+		Name: "Amazon CloudFront", Category: "CDN",
+		// x-cache 如 Hit from cloudfront / RefreshHit from cloudfront
+		Headers: map[string]string{"x-amz-cf-id": "", "x-amz-cf-pop": "", "via": "cloudfront", "x-cache": " from cloudfront$"},
+	},
+	{
+		Name: "Google Cloud CDN", Category: "CDN",
+		Headers: map[string]string{"via": "^1\\.1 google$"},
+	},
+	{
+		Name: "Azure Front Door / CDN", Category: "CDN",
+		Headers: map[string]string{"x-azure-ref": "", "x-fd-healthprobe": ""},
+	},
+	{
+		Name: "Vercel", Category: "CDN",
+		Headers: map[string]string{"server": "^Vercel$", "x-vercel-id": "", "x-vercel-cache": ""},
+	},
+	{
+		Name: "Netlify", Category: "CDN",
+		Headers: map[string]string{"server": "^Netlify$", "x-nf-request-id": ""},
+	},
+	{
+		Name: "GitHub Pages", Category: "CDN",
+		Headers: map[string]string{"server": "^GitHub\\.com$", "x-github-request-id": ""},
+	},
+	{
+		Name: "BunnyCDN", Category: "CDN",
+		Headers: map[string]string{"server": "^BunnyCDN", "cdn-pullzone": "", "cdn-requestid": ""},
+	},
+	{
+		Name: "Varnish", Category: "CDN",
+		// 单独的 x-cache: HIT 过于常见, 只认明确带 varnish 的值
+		Headers: map[string]string{"x-varnish": "", "via": "varnish", "x-cache": "varnish"},
+	},
+	{
+		Name: "Alibaba Cloud CDN", Category: "CDN",
+		Headers: map[string]string{"eagleid": "", "x-swift-cachetime": "", "ali-swift-global-savetime": "", "via": "cache\\d+\\.(?:l2|cn)"},
+	},
+	{
+		Name: "Tencent Cloud CDN", Category: "CDN",
+		Headers: map[string]string{"x-nws-log-uuid": "", "server": "^NWS_"},
+	},
+	{
+		Name: "Baidu Yunjiasu", Category: "CDN",
+		Headers: map[string]string{"server": "^yunjiasu"},
+	},
+	{
+		Name: "Wangsu", Category: "CDN",
+		Headers: map[string]string{"x-via": "wangsu|chinanetcenter", "x-ws-request-id": ""},
+	},
+	{
+		Name: "DDoS-Guard", Category: "WAF",
+		Headers: map[string]string{"server": "^ddos-guard"},
+		Cookies: []string{"^__ddg\\d+_?$", "^__ddgid_?$", "^__ddgmark_?$"},
+	},
+	{
+		Name: "Sucuri", Category: "WAF",
+		Headers: map[string]string{"x-sucuri-id": "", "x-sucuri-cache": "", "server": "^Sucuri/Cloudproxy"},
+	},
+	{
+		Name: "Imperva Incapsula", Category: "WAF",
+		Headers: map[string]string{"x-iinfo": "", "x-cdn": "incapsula"},
+		Cookies: []string{"^incap_ses_", "^visid_incap_", "^nlbi_"},
+	},
+	{
+		Name: "AWS WAF", Category: "WAF",
+		Headers: map[string]string{"x-amzn-waf-action": ""},
+		Cookies: []string{"^aws-waf-token$"},
+	},
+	{
+		Name: "Alibaba Cloud WAF", Category: "WAF",
+		Cookies: []string{"^aliyungf_tc$", "^acw_tc$", "^acw_sc__v2$"},
+	},
+	{
+		Name: "Tencent Cloud WAF", Category: "WAF",
+		Cookies: []string{"^waf_cookie$"},
+	},
+}
+
+// 质询页 / 拦截页识别规则列表
+var ChallengeRules = []ChallengeRule{
+	{
+		Name:    "Cloudflare Challenge",
+		Headers: map[string]string{"cf-mitigated": "challenge"},
+		Title:   "^(?:Just a moment\\.\\.\\.|Attention Required! \\| Cloudflare|请稍候…)$",
+		Body:    []string{"cf-browser-verification", "window\\._cf_chl_opt"},
+	},
+	{
+		Name:  "DDoS-Guard Challenge",
+		Title: "^DDoS-Guard$",
+		Body:  []string{"check\\.ddos-guard\\.net", "ddos-guard/js-challenge"},
+	},
+	{
+		Name:  "Sucuri Firewall",
+		Title: "Sucuri WebSite Firewall",
+		Body:  []string{"sucuri\\.net/privacy-policy", "cloudproxy@sucuri\\.net"},
+	},
+	{
+		Name: "Imperva Incapsula",
+		Body: []string{"_Incapsula_Resource", "Incapsula incident ID"},
+	},
+	{
+		Name: "Akamai Bot Manager",
+		Body: []string{"errors\\.edgesuite\\.net", "/_sec/cp_challenge/"},
+	},
+	{
+		Name: "AWS WAF Challenge",
+		Body: []string{"awswaf\\.com/.+/challenge\\.js", "AwsWafIntegration"},
+	},
+	{
+		Name: "Alibaba Cloud WAF",
+		Body: []string{"aliyun_waf_aa", "var arg1='[0-9A-F]{40}'"},
+	},
+	{
+		Name: "Tencent Cloud WAF",
+		Body: []string{"waf\\.tencent-cloud\\.com", "tencent-cloud\\.com/waf"},
+	},
+	{
+		Name:  "Captcha Wall",
+		Title: "(?:captcha|verify you are human|are you a robot|human verification|人机验证|安全验证|访问验证)",
+	},
+}
@@ -0,0 +1,51 @@
+package models
+
+import "regexp"
+
+// 指纹规则 (Wappalyzer 风格), 从数据文件加载
+// 模式支持 `正则\;version:\1\;confidence:50` 写法
+type FingerprintRule struct {
+	Name     string            `yaml:"name"`     // 技术名称
+	Category string            `yaml:"category"` // 分类 CMS / Forum / Framework / WebServer ...
+	Website  string            `yaml:"website"`  // 官网
+	Headers  map[string]string `yaml:"headers"`  // 响应头名称 -> 模式
+	Cookies  map[string]string `yaml:"cookies"`  // cookie 名称 -> 模式
+	Meta     map[string]string `yaml:"meta"`     // meta name -> 模式, 如 generator
+	Scripts  []string          `yaml:"scripts"`  // script src 模式
+	HTML     []string          `yaml:"html"`     // 页面内容模式
+	Implies  []string          `yaml:"implies"`  // 隐含的其他技术
+}
+
+// 指纹规则文件
+type FingerprintRuleFile struct {
+	Technologies []FingerprintRule `yaml:"technologies"`
+}
+
+// 编译后的单条模式
+type FingerprintPattern struct {
+	Regexp     *regexp.Regexp
+	Version    string // 版本模板, 如 \1
+	Confidence int    // 置信度 0-100
+}
+
+// 编译后的指纹规则
+type CompiledFingerprint struct {
+	Name     string
+	Category string
+	Website  string
+	Headers  map[string][]FingerprintPattern
+	Cookies  map[string][]FingerprintPattern
+	Meta     map[string][]FingerprintPattern
+	Scripts  []FingerprintPattern
+	HTML     []FingerprintPattern
+	Implies  []string
+}
+
+// 识别出的技术
+type Technology struct {
+	Name       string `json:"name"`       // 技术名称
+	Category   string `json:"category"`   // 分类
+	Version    string `json:"version"`    // 版本
+	Confidence int    `json:"confidence"` // 置信度
+	Website    string `json:"website"`    // 官网
+}
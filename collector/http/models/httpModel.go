@@ -20,6 +20,13 @@ type HTTPModel struct {
 	Redirects     []string            `json:"redirects"`     // 重定向链
 	Headers       map[string][]string `json:"headers"`       // 响应头
 	Meta          map[string]string   `json:"meta"`          // meta 标签
+	Technologies  []Technology        `json:"technologies"`  // 识别出的技术栈
+	RemoteAddr    string              `json:"remoteAddr"`    // 实际连接地址
+	Hops          []HopModel          `json:"hops"`          // 每一跳实际连接的地址
+	Edges         []EdgeProvider      `json:"edges"`         // 识别出的 CDN / WAF
+	Challenge     string              `json:"challenge"`     // 命中的质询页 / 拦截页
+	Label         string              `json:"label"`         // 站点状态 live parked for-sale suspended default-page soft-404
+	LabelReasons  []string            `json:"labelReasons"`  // 判定依据
 
 	// TLS
 	TLSVersion    string    `json:"tlsVersion"`    // TLS 版本
@@ -50,6 +57,13 @@ type HTTPSaveModel struct {
 	Redirects     []string            `json:"redirects"`     // 重定向链
 	Headers       map[string][]string `json:"headers"`       // 响应头
 	Meta          map[string]string   `json:"meta"`          // meta 标签
+	Technologies  []Technology        `json:"technologies"`  // 识别出的技术栈
+	RemoteAddr    string              `json:"remoteAddr"`    // 实际连接地址
+	Hops          []HopModel          `json:"hops"`          // 每一跳实际连接的地址
+	Edges         []EdgeProvider      `json:"edges"`         // 识别出的 CDN / WAF
+	Challenge     string              `json:"challenge"`     // 命中的质询页 / 拦截页
+	Label         string              `json:"label"`         // 站点状态 live parked for-sale suspended default-page soft-404
+	LabelReasons  []string            `json:"labelReasons"`  // 判定依据
 
 	// TLS
 	TLSVersion    string   `json:"tlsVersion"`    // TLS 版本
@@ -65,6 +79,19 @@ type HTTPSaveModel struct {
 	CertIsCA      bool     `json:"certIsCA"`      // 是否CA
 }
 
+// 单跳连接信息
+type HopModel struct {
+	Url        string `json:"url"`        // 请求 url
+	RemoteAddr string `json:"remoteAddr"` // 连接地址 ip:port
+	IP         string `json:"ip"`         // 目标 IP, 走代理时为空
+	ViaProxy   bool   `json:"viaProxy"`   // 是否经过代理
+	Reused     bool   `json:"reused"`     // 是否复用连接
+	Country    string `json:"country"`    // IP 国家
+	City       string `json:"city"`       // IP 城市
+	ASN        string `json:"asn"`        // IP 所属 ASN
+	ISP        string `json:"isp"`        // ISP 名称
+}
+
 // TLS 版本映射
 var TlsVersionMap = map[uint16]string{
 	tls.VersionTLS10: "TLS1.0",
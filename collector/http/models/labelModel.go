@@ -0,0 +1,111 @@
+package models
+
+// 站点状态标签
+const (
+	SiteLabelLive        = "live"         // 正常
+	SiteLabelParked      = "parked"       // 停放页
+	SiteLabelForSale     = "for-sale"     // 域名出售
+	SiteLabelSuspended   = "suspended"    // 主机被暂停
+	SiteLabelDefaultPage = "default-page" // 服务器默认页
+	SiteLabelSoft404     = "soft-404"     // 返回 200 的 404 页
+)
+
+// 站点状态识别规则
+type LabelRule struct {
+	Label string
+	Title []string // 标题正则
+	Body  []string // 页面内容正则
+}
+
+// 停放服务商 NS 后缀
+var ParkingNameservers = []string{
+	"sedoparking.com", "parkingcrew.net", "bodis.com", "above.com", "parklogic.com",
+	"dan.com", "afternic.com", "uniregistrymarket.link", "namefind.com", "cashparking.com",
+	"parking.reg.ru", "parkingpage.namecheap.com", "dnsowl.com", "ztomy.com",
+}
+
+// 停放 / 出售平台跳转目标
+type ParkingRedirect struct {
+	Host  string // 主机名, 匹配自身或其子域名
+	Label string
+}
+
+// 停放 / 出售平台跳转目标, 按顺序匹配
+var ParkingRedirectHosts = []ParkingRedirect{
+	{Host: "sedo.com", Label: SiteLabelForSale},
+	{Host: "dan.com", Label: SiteLabelForSale},
+	{Host: "afternic.com", Label: SiteLabelForSale},
+	{Host: "hugedomains.com", Label: SiteLabelForSale},
+	{Host: "buydomains.com", Label: SiteLabelForSale},
+	{Host: "undeveloped.com", Label: SiteLabelForSale},
+	{Host: "atom.com", Label: SiteLabelForSale},
+	{Host: "squadhelp.com", Label: SiteLabelForSale},
+	{Host: "domainmarket.com", Label: SiteLabelForSale},
+	{Host: "sav.com", Label: SiteLabelForSale},
+	{Host: "efty.com", Label: SiteLabelForSale},
+	{Host: "spaceship.com", Label: SiteLabelForSale},
+	{Host: "parkingcrew.net", Label: SiteLabelParked},
+	{Host: "bodis.com", Label: SiteLabelParked},
+	{Host: "sedoparking.com", Label: SiteLabelParked},
+	{Host: "above.com", Label: SiteLabelParked},
+	{Host: "parklogic.com", Label: SiteLabelParked},
+	{Host: "domainparking.ru", Label: SiteLabelParked},
+	{Host: "suspended-page.com", Label: SiteLabelSuspended},
+}
+
+// 停放商常见的 ww1.<domain> 跳转
+const ParkingRedirectPrefix = "ww1."
+
+// cPanel 暂停页路径
+const SuspendedPagePath = "/cgi-sys/suspendedpage.cgi"
+
+// 页面识别规则, 按优先级排列
+var LabelRules = []LabelRule{
+	{
+		Label: SiteLabelSuspended,
+		Title: []string{"^account suspended$", "suspended domain", "网站暂停访问", "站点已暂停", "该网站已被停止"},
+		Body: []string{
+			"this account has been suspended", "/cgi-sys/suspendedpage\\.cgi",
+			"website (?:is )?(?:temporarily )?suspended", "hosting account (?:is|has been) suspended",
+			"该网站(?:已|暂时)?被(?:暂停|停止)", "站点已(?:暂停|停止|过期)", "空间已(?:到期|过期|停止)",
+		},
+	},
+	{
+		Label: SiteLabelForSale,
+		Title: []string{"(?:domain|is) for sale", "buy this domain", "域名(?:出售|转让)", "域名正在(?:出售|转让)"},
+		Body: []string{
+			"this domain (?:name )?(?:is|may be) for sale", "buy this domain", "make an offer on this domain",
+			"the domain .{1,80} is for sale", "inquire about this domain",
+			"该域名(?:正在|可以)?(?:出售|转让)", "本域名(?:出售|转让)",
+		},
+	},
+	{
+		Label: SiteLabelParked,
+		Title: []string{"^parked domain", "domain parking", "future home of something quite cool", "^coming soon$"},
+		Body: []string{
+			"this domain (?:name )?(?:is|has been) parked", "parked free(?:,)? courtesy of", "domain parking",
+			"sedoparking\\.com", "parkingcrew\\.net", "bodis\\.com", "window\\.park\\s*=",
+			"this web page is parked free", "parkingpage\\.namecheap\\.com",
+			"域名停放", "该域名已被停放",
+		},
+	},
+	{
+		Label: SiteLabelDefaultPage,
+		Title: []string{
+			"^welcome to nginx!?$", "^apache2 (?:ubuntu|debian) default page", "^test page for the (?:apache|nginx)",
+			"^iis windows server$", "^it works!?$", "^welcome to (?:centos|almalinux|rocky linux)",
+			"^default web site page$", "^web server's default page$", "^plesk", "^没有找到站点$", "^恭喜，站点创建成功",
+			"^openresty", "^welcome to tengine",
+		},
+		Body: []string{
+			"if you see this page, the nginx web server is successfully installed",
+			"this is the default welcome page used to test the correct operation of the apache2 server",
+			"this page is used to test the proper operation of the apache http server",
+			"您的请求在web服务器中没有找到对应的站点", "这是默认index\\.html",
+		},
+	},
+	{
+		Label: SiteLabelSoft404,
+		Title: []string{"^404\\b", "\\b404 not found\\b", "page not found", "not found$", "页面不存在", "找不到(?:该)?页面", "页面未找到"},
+	},
+}
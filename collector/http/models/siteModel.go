@@ -24,11 +24,13 @@ const TableNameGfnCollectorLogHTTP = "gfn_collector_log_http"
 
 // GfnCollectorLogHTTP mapped from table <gfn_collector_log_http>
 type GfnCollectorLogHTTP struct {
-	ID         int64        `gorm:"column:id;type:bigint;primaryKey;comment:http请求日志表" json:"id"`                                     // http请求日志表
-	Name       string       `gorm:"column:name;type:character varying(255);not null;comment:域名" json:"name"`                          // 域名
-	Info       string       `gorm:"column:info;type:json;not null;comment:日志内容" json:"info"`                                          // 日志内容
-	Status     string       `gorm:"column:status;type:character varying(20);not null;comment:请求状态 success failure" json:"status"`     // 请求状态 success failure
-	CreateTime cm.LocalTime `gorm:"column:create_time;type:int;type:unsigned;not null;autoCreateTime;comment:请求时间" json:"createTime"` // 请求时间
+	ID         int64        `gorm:"column:id;type:bigint;primaryKey;comment:http请求日志表" json:"id"`                                           // http请求日志表
+	Name       string       `gorm:"column:name;type:character varying(255);not null;comment:域名" json:"name"`                                // 域名
+	Info       string       `gorm:"column:info;type:json;not null;comment:日志内容" json:"info"`                                                // 日志内容
+	RemoteIP   *string      `gorm:"column:remote_ip;type:character varying(64);comment:实际连接 IP" json:"remoteIp"`                            // 实际连接 IP
+	Label      *string      `gorm:"column:label;type:character varying(20);comment:站点状态" json:"label"`                                      // 站点状态
+	Status     string       `gorm:"column:status;type:character varying(20);not null;comment:请求状态 success failure challenge" json:"status"` // 请求状态 success failure challenge
+	CreateTime cm.LocalTime `gorm:"column:create_time;type:int;type:unsigned;not null;autoCreateTime;comment:请求时间" json:"createTime"`       // 请求时间
 }
 
 // TableName GfnCollectorLogHTTP's table name
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/http/dao"
+	"github.com/GoFurry/gofurry-nav-collector/collector/http/models"
+	"github.com/GoFurry/gofurry-nav-collector/common/log"
+	cm "github.com/GoFurry/gofurry-nav-collector/common/models"
+	cs "github.com/GoFurry/gofurry-nav-collector/common/service"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"github.com/sourcegraph/conc/pool"
+)
+
+var discoveryThread = pool.New().WithMaxGoroutines(env.GetServerConfig().Collector.Request.RequestThread)
+var discoveryWG sync.WaitGroup
+
+// ============== HTTP模块 - 访问入口发现 ==============
+
+// 探测每个域名的 http/https 及 www/apex 入口, 校验 tls / prefix 配置
+func Discover() {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error(fmt.Sprintf("receive Discover recover: %v", err))
+		}
+	}()
+
+	domainList, err := dao.GetHTTPDao().GetList()
+	if err != nil {
+		log.Error("Discover 获取站点列表失败: " + err.GetMsg())
+		return
+	}
+	if len(domainList) < 1 {
+		log.Info("Discover 站点列表为空")
+		return
+	}
+
+	log.Info("访问入口探测开始")
+	for _, v := range domainList {
+		discoveryWG.Add(1)
+		discoveryThread.Go(getDiscoveryResult(v))
+	}
+	discoveryWG.Wait()
+	log.Info("访问入口探测结束")
+}
+
+// 探测单个域名并保存结果
+func getDiscoveryResult(site models.GfnCollectorDomain) func() {
+	return func() {
+		defer func() {
+			if err := recover(); err != nil {
+				log.Error(fmt.Sprintf("receive DiscoveryThread recover: %v", err))
+			}
+		}()
+		defer discoveryWG.Done()
+
+		result := performDiscovery(site)
+
+		// 自动修正配置
+		if result.NeedCorrection && env.GetServerConfig().Collector.Request.DiscoveryApply {
+			var prefix *string
+			if result.SuggestedPrefix != "" {
+				prefix = &result.SuggestedPrefix
+			}
+			if err := dao.GetHTTPDao().UpdateVariant(site.ID, result.SuggestedTLS, prefix); err != nil {
+				log.Error("修正域名配置失败: ", err.GetMsg())
+			} else {
+				result.Applied = true
+				log.Info(fmt.Sprintf("%s 配置已修正: tls %s -> %s, prefix %q -> %q", site.Name,
+					result.CurrentTLS, result.SuggestedTLS, result.CurrentPrefix, result.SuggestedPrefix))
+			}
+		} else if result.NeedCorrection {
+			log.Warn(fmt.Sprintf("%s 配置建议修正: tls %s -> %s, prefix %q -> %q", site.Name,
+				result.CurrentTLS, result.SuggestedTLS, result.CurrentPrefix, result.SuggestedPrefix))
+		}
+
+		jsonResult, jsonErr := json.Marshal(result)
+		if jsonErr != nil {
+			log.Error("json转换错误: ", jsonErr)
+			return
+		}
+		if gfError := cs.Set("discovery:"+site.Name, string(jsonResult)); gfError != nil {
+			log.Error("存储访问入口探测结果失败: ", gfError.GetMsg())
+		}
+	}
+}
+
+// 执行访问入口探测
+func performDiscovery(site models.GfnCollectorDomain) (res models.DiscoveryModel) {
+	res.Domain = site.Name
+	res.CurrentTLS = site.TLS
+	if site.Prefix != nil {
+		res.CurrentPrefix = *site.Prefix
+	}
+	res.CheckTime = cm.LocalTime(time.Now())
+	prefixes := discoveryPrefixes(res.CurrentPrefix)
+
+	// 并行探测全部入口
+	var mu sync.Mutex
+	var probeWG sync.WaitGroup
+	variants := make(map[string]models.VariantModel)
+	for _, scheme := range models.DiscoverySchemes {
+		for _, prefix := range prefixes {
+			probeWG.Add(1)
+			go func(target string) {
+				defer probeWG.Done()
+				variant := probeVariant(target, site.Proxy == "1")
+				mu.Lock()
+				variants[target] = variant
+				mu.Unlock()
+			}(scheme + "://" + prefix + site.Name)
+		}
+	}
+	probeWG.Wait()
+
+	// 按优先级排列结果, 统计最终落地的入口
+	res.Variants = []models.VariantModel{}
+	landing := make(map[string]int) // 全部入口的落地
+	own := make(map[string]int)     // 当前配置的入口的落地
+	res.HTTPUpgrade = true
+	hasHTTP := false
+	for _, scheme := range models.DiscoverySchemes {
+		for _, prefix := range prefixes {
+			variant := variants[scheme+"://"+prefix+site.Name]
+			res.Variants = append(res.Variants, variant)
+			if !variant.Reachable {
+				continue
+			}
+			if scheme == "http" {
+				hasHTTP = true
+				res.HTTPUpgrade = res.HTTPUpgrade && variant.UpgradeHTTPS
+			}
+			// 错误页不作为可用入口
+			if variant.StatusCode >= 400 {
+				continue
+			}
+			if final := landingOf(variant.FinalUrl, site.Name, prefixes); final != "" {
+				landing[final]++
+				if prefix == res.CurrentPrefix {
+					own[final]++
+				}
+			}
+		}
+	}
+	if !hasHTTP {
+		res.HTTPUpgrade = false
+	}
+
+	// 当前入口可用时以它的落地为准, 只有当前入口不可用时才参考其他入口
+	// 自定义前缀 (如 bbs.) 与 apex / www 是不同的站点, 不能互相替换
+	standard := isDiscoveryPrefix(res.CurrentPrefix)
+	best := ""
+	switch {
+	case len(own) > 0:
+		best = bestLanding(own, prefixes, site.Name)
+	case standard:
+		best = bestLanding(landing, prefixes, site.Name)
+	}
+	if best == "" {
+		// 没有可用入口时保持原配置
+		res.SuggestedTLS = res.CurrentTLS
+		res.SuggestedPrefix = res.CurrentPrefix
+		return
+	}
+
+	scheme, host, _ := strings.Cut(best, "://")
+	res.SuggestedPrefix = strings.TrimSuffix(host, site.Name)
+	if !standard && res.SuggestedPrefix != res.CurrentPrefix {
+		// 自定义前缀跳转到了其他入口, 保留前缀, 只按自身可用的协议修正 tls
+		res.SuggestedPrefix = res.CurrentPrefix
+		scheme = reachableScheme(variants, res.CurrentPrefix+site.Name)
+	}
+	switch scheme {
+	case "https":
+		res.SuggestedTLS = "1"
+	case "http":
+		res.SuggestedTLS = "0"
+	default:
+		res.SuggestedTLS = res.CurrentTLS
+	}
+	res.NeedCorrection = res.SuggestedTLS != res.CurrentTLS || res.SuggestedPrefix != res.CurrentPrefix
+	return
+}
+
+// 探测的前缀, 当前配置的前缀排在最前, 同票时优先保持原配置
+func discoveryPrefixes(current string) []string {
+	prefixes := []string{current}
+	for _, prefix := range models.DiscoveryPrefixes {
+		if prefix != current {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// 是否为 apex / www 前缀
+func isDiscoveryPrefix(prefix string) bool {
+	for _, p := range models.DiscoveryPrefixes {
+		if p == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// 选出落地次数最多的入口, 同票时按探测优先级
+func bestLanding(counts map[string]int, prefixes []string, domain string) string {
+	best, bestCount := "", 0
+	for _, scheme := range models.DiscoverySchemes {
+		for _, prefix := range prefixes {
+			key := scheme + "://" + prefix + domain
+			if counts[key] > bestCount {
+				best, bestCount = key, counts[key]
+			}
+		}
+	}
+	return best
+}
+
+// 主机自身可用的协议, 按优先级, 都不可用时返回空
+func reachableScheme(variants map[string]models.VariantModel, host string) string {
+	for _, scheme := range models.DiscoverySchemes {
+		if variant := variants[scheme+"://"+host]; variant.Reachable && variant.StatusCode < 400 {
+			return scheme
+		}
+	}
+	return ""
+}
+
+// 返回落地 url 对应的入口 scheme://host, 跳出本域名时返回空
+func landingOf(finalURL string, domain string, prefixes []string) string {
+	u, err := url.Parse(finalURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, prefix := range prefixes {
+		if host == strings.ToLower(prefix+domain) {
+			return u.Scheme + "://" + prefix + domain
+		}
+	}
+	return ""
+}
+
+// 探测单个入口, 记录重定向链和最终落地地址
+func probeVariant(target string, useProxy bool) (variant models.VariantModel) {
+	variant.Url = target
+	variant.Redirects = []string{}
+
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+	}
+	if useProxy {
+		proxyURL, _ := url.Parse(env.GetServerConfig().Collector.Proxy)
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   15 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			variant.Redirects = append(variant.Redirects, req.URL.String())
+			if len(via) >= 10 {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+
+	req, err := http.NewRequest("GET", target, nil)
+	if err != nil {
+		variant.Error = err.Error()
+		return
+	}
+	for k, v := range models.HeadersMap {
+		req.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		variant.Error = err.Error()
+		return
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	variant.ResponseTime = time.Since(start).Milliseconds()
+	variant.Reachable = true
+	variant.StatusCode = int64(resp.StatusCode)
+	variant.FinalUrl = resp.Request.URL.String()
+	variant.UpgradeHTTPS = strings.HasPrefix(target, "http://") && resp.Request.URL.Scheme == "https"
+	return
+}
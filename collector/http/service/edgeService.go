@@ -0,0 +1,128 @@
+package service
+
+import (
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/http/models"
+	cm "github.com/GoFurry/gofurry-nav-collector/common/models"
+	cs "github.com/GoFurry/gofurry-nav-collector/common/service"
+)
+
+// 编译后的 CDN / WAF 规则
+type edgeMatcher struct {
+	rule    models.EdgeRule
+	headers map[string]*regexp.Regexp
+	cookies []*regexp.Regexp
+}
+
+// 编译后的质询页规则
+type challengeMatcher struct {
+	rule    models.ChallengeRule
+	headers map[string]*regexp.Regexp
+	title   *regexp.Regexp
+	body    []*regexp.Regexp
+}
+
+var edgeMatchers = compileEdgeRules()
+var challengeMatchers = compileChallengeRules()
+
+// ============== HTTP模块 - CDN / WAF 识别 ==============
+
+func compileEdgeRules() []edgeMatcher {
+	matchers := make([]edgeMatcher, 0, len(models.EdgeRules))
+	for _, rule := range models.EdgeRules {
+		m := edgeMatcher{rule: rule, headers: make(map[string]*regexp.Regexp)}
+		for k, v := range rule.Headers {
+			m.headers[k] = regexp.MustCompile("(?i)" + v)
+		}
+		for _, v := range rule.Cookies {
+			m.cookies = append(m.cookies, regexp.MustCompile("(?i)"+v))
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers
+}
+
+func compileChallengeRules() []challengeMatcher {
+	matchers := make([]challengeMatcher, 0, len(models.ChallengeRules))
+	for _, rule := range models.ChallengeRules {
+		m := challengeMatcher{rule: rule, headers: make(map[string]*regexp.Regexp)}
+		for k, v := range rule.Headers {
+			m.headers[k] = regexp.MustCompile("(?i)" + v)
+		}
+		if rule.Title != "" {
+			m.title = regexp.MustCompile("(?i)" + rule.Title)
+		}
+		for _, v := range rule.Body {
+			m.body = append(m.body, regexp.MustCompile("(?i)"+v))
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers
+}
+
+// 根据响应头、cookie 和节点 IP 识别 CDN / WAF
+// IP 段和 ASN 使用服务商数据, ip 为空时 (如走代理) 跳过
+func detectEdges(header http.Header, cookies []*http.Cookie, ip net.IP) []models.EdgeProvider {
+	res := []models.EdgeProvider{}
+	var provider cm.ProviderMatch
+	if ip != nil {
+		provider = cs.DetectProvider(ip, cs.LookupGeoIP(ip).ASNumber, nil)
+	}
+	providerMerged := false
+	for _, m := range edgeMatchers {
+		var evidence []string
+		for name, re := range m.headers {
+			for _, value := range header.Values(name) {
+				if re.MatchString(value) {
+					evidence = append(evidence, "header "+name+": "+value)
+					break
+				}
+			}
+		}
+		for _, c := range cookies {
+			for _, re := range m.cookies {
+				if re.MatchString(c.Name) {
+					evidence = append(evidence, "cookie "+c.Name)
+					break
+				}
+			}
+		}
+		if provider.Name != "" && provider.Name == m.rule.Name {
+			evidence = append(evidence, provider.Evidence)
+			providerMerged = true
+		}
+		if len(evidence) > 0 {
+			res = append(res, models.EdgeProvider{Name: m.rule.Name, Category: m.rule.Category, Evidence: evidence})
+		}
+	}
+	// 没有对应规则的 CDN 只凭 IP 段 / ASN 识别, 云主机和虚拟主机不算
+	if !providerMerged && provider.Category == cm.ProviderCDN {
+		res = append(res, models.EdgeProvider{Name: provider.Name, Category: provider.Category, Evidence: []string{provider.Evidence}})
+	}
+	return res
+}
+
+// 识别质询页 / 拦截页, 返回命中的规则名称
+func detectChallenge(header http.Header, title string, body []byte) string {
+	title = strings.TrimSpace(title)
+	for _, m := range challengeMatchers {
+		for name, re := range m.headers {
+			if value := header.Get(name); value != "" && re.MatchString(value) {
+				return m.rule.Name
+			}
+		}
+		if m.title != nil && title != "" && m.title.MatchString(title) {
+			return m.rule.Name
+		}
+		for _, re := range m.body {
+			if re.Match(body) {
+				return m.rule.Name
+			}
+		}
+	}
+	return ""
+}
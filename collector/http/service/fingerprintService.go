@@ -0,0 +1,252 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/http/models"
+	"github.com/GoFurry/gofurry-nav-collector/common"
+	"github.com/GoFurry/gofurry-nav-collector/common/log"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"gopkg.in/yaml.v2"
+)
+
+// 指纹规则 文件更新后自动重新加载
+var fingerprintRules []models.CompiledFingerprint
+var fingerprintModTime time.Time
+var fingerprintRWLock sync.RWMutex
+
+var (
+	reMetaTag   = regexp.MustCompile(`(?is)<meta\s+[^>]*>`)
+	reMetaName  = regexp.MustCompile(`(?i)(?:name|property)\s*=\s*["']([^"']+)["']`)
+	reMetaValue = regexp.MustCompile(`(?i)content\s*=\s*["']([^"']*)["']`)
+	reScriptSrc = regexp.MustCompile(`(?i)<script[^>]+src\s*=\s*["']([^"']+)["']`)
+	reVersion   = regexp.MustCompile(`\\(\d)`)
+)
+
+// ============== HTTP模块 - 指纹规则加载 ==============
+
+// 加载指纹规则, 文件未变化时跳过
+func loadFingerprintRules() common.GFError {
+	path := env.GetServerConfig().Collector.Request.FingerprintPath
+	if path == "" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return common.NewServiceError("读取指纹规则文件失败: " + err.Error())
+	}
+
+	fingerprintRWLock.RLock()
+	unchanged := info.ModTime().Equal(fingerprintModTime)
+	fingerprintRWLock.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return common.NewServiceError("读取指纹规则文件失败: " + err.Error())
+	}
+	var ruleFile models.FingerprintRuleFile
+	if err = yaml.Unmarshal(fileBytes, &ruleFile); err != nil {
+		return common.NewServiceError("解析指纹规则文件失败: " + err.Error())
+	}
+
+	rules := make([]models.CompiledFingerprint, 0, len(ruleFile.Technologies))
+	for _, rule := range ruleFile.Technologies {
+		rules = append(rules, compileFingerprint(rule))
+	}
+
+	fingerprintRWLock.Lock()
+	fingerprintRules = rules
+	fingerprintModTime = info.ModTime()
+	fingerprintRWLock.Unlock()
+
+	log.Info(fmt.Sprintf("指纹规则加载完成, 共 %d 条", len(rules)))
+	return nil
+}
+
+// 编译单条指纹规则
+func compileFingerprint(rule models.FingerprintRule) models.CompiledFingerprint {
+	compiled := models.CompiledFingerprint{
+		Name:     rule.Name,
+		Category: rule.Category,
+		Website:  rule.Website,
+		Headers:  compilePatternMap(rule.Name, rule.Headers),
+		Cookies:  compilePatternMap(rule.Name, rule.Cookies),
+		Meta:     compilePatternMap(rule.Name, rule.Meta),
+		Implies:  rule.Implies,
+	}
+	for _, p := range rule.Scripts {
+		if pattern, ok := compilePattern(rule.Name, p); ok {
+			compiled.Scripts = append(compiled.Scripts, pattern)
+		}
+	}
+	for _, p := range rule.HTML {
+		if pattern, ok := compilePattern(rule.Name, p); ok {
+			compiled.HTML = append(compiled.HTML, pattern)
+		}
+	}
+	return compiled
+}
+
+func compilePatternMap(name string, patterns map[string]string) map[string][]models.FingerprintPattern {
+	res := make(map[string][]models.FingerprintPattern)
+	for k, p := range patterns {
+		if pattern, ok := compilePattern(name, p); ok {
+			key := strings.ToLower(k)
+			res[key] = append(res[key], pattern)
+		}
+	}
+	return res
+}
+
+// 解析 `正则\;version:\1\;confidence:50`
+func compilePattern(name string, raw string) (models.FingerprintPattern, bool) {
+	parts := strings.Split(raw, `\;`)
+	pattern := models.FingerprintPattern{Confidence: 100}
+	re, err := regexp.Compile("(?i)" + parts[0])
+	if err != nil {
+		log.Warn(name + " 指纹规则无效: " + raw)
+		return pattern, false
+	}
+	pattern.Regexp = re
+	for _, attr := range parts[1:] {
+		k, v, found := strings.Cut(attr, ":")
+		if !found {
+			continue
+		}
+		switch k {
+		case "version":
+			pattern.Version = v
+		case "confidence":
+			if c, convErr := strconv.Atoi(v); convErr == nil {
+				pattern.Confidence = c
+			}
+		}
+	}
+	return pattern, true
+}
+
+// ============== HTTP模块 - 指纹识别 ==============
+
+// 根据响应头、cookie、meta、脚本和页面内容识别站点技术栈
+func detectTechnologies(header http.Header, cookies []*http.Cookie, body []byte) []models.Technology {
+	fingerprintRWLock.RLock()
+	rules := fingerprintRules
+	fingerprintRWLock.RUnlock()
+	if len(rules) == 0 {
+		return []models.Technology{}
+	}
+
+	// 预先提取 meta 和 script
+	html := string(body)
+	metas := make(map[string][]string)
+	for _, tag := range reMetaTag.FindAllString(html, -1) {
+		name := reMetaName.FindStringSubmatch(tag)
+		value := reMetaValue.FindStringSubmatch(tag)
+		if len(name) > 1 && len(value) > 1 {
+			key := strings.ToLower(name[1])
+			metas[key] = append(metas[key], value[1])
+		}
+	}
+	var scripts []string
+	for _, m := range reScriptSrc.FindAllStringSubmatch(html, -1) {
+		scripts = append(scripts, m[1])
+	}
+	cookieMap := make(map[string]string)
+	for _, c := range cookies {
+		cookieMap[strings.ToLower(c.Name)] = c.Value
+	}
+
+	detected := make(map[string]*models.Technology)
+	ruleIndex := make(map[string]models.CompiledFingerprint)
+	for _, rule := range rules {
+		ruleIndex[rule.Name] = rule
+		for name, patterns := range rule.Headers {
+			for _, value := range header.Values(name) {
+				matchPatterns(detected, rule, patterns, value)
+			}
+		}
+		for name, patterns := range rule.Cookies {
+			if value, ok := cookieMap[name]; ok {
+				matchPatterns(detected, rule, patterns, value)
+			}
+		}
+		for name, patterns := range rule.Meta {
+			for _, value := range metas[name] {
+				matchPatterns(detected, rule, patterns, value)
+			}
+		}
+		for _, src := range scripts {
+			matchPatterns(detected, rule, rule.Scripts, src)
+		}
+		matchPatterns(detected, rule, rule.HTML, html)
+	}
+
+	// 处理隐含技术
+	queue := make([]string, 0, len(detected))
+	for name := range detected {
+		queue = append(queue, name)
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, implied := range ruleIndex[name].Implies {
+			if _, ok := detected[implied]; ok {
+				continue
+			}
+			tech := &models.Technology{Name: implied, Confidence: detected[name].Confidence}
+			if rule, ok := ruleIndex[implied]; ok {
+				tech.Category = rule.Category
+				tech.Website = rule.Website
+			}
+			detected[implied] = tech
+			queue = append(queue, implied)
+		}
+	}
+
+	res := make([]models.Technology, 0, len(detected))
+	for _, tech := range detected {
+		res = append(res, *tech)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// 匹配一组模式, 累加置信度并提取版本
+func matchPatterns(detected map[string]*models.Technology, rule models.CompiledFingerprint, patterns []models.FingerprintPattern, value string) {
+	for _, pattern := range patterns {
+		matches := pattern.Regexp.FindStringSubmatch(value)
+		if matches == nil {
+			continue
+		}
+		tech, ok := detected[rule.Name]
+		if !ok {
+			tech = &models.Technology{Name: rule.Name, Category: rule.Category, Website: rule.Website}
+			detected[rule.Name] = tech
+		}
+		tech.Confidence = min(tech.Confidence+pattern.Confidence, 100)
+		if pattern.Version != "" {
+			version := reVersion.ReplaceAllStringFunc(pattern.Version, func(ref string) string {
+				idx, _ := strconv.Atoi(ref[1:])
+				if idx < len(matches) {
+					return matches[idx]
+				}
+				return ""
+			})
+			// 保留更长(更精确)的版本号
+			if len(version) > len(tech.Version) {
+				tech.Version = version
+			}
+		}
+	}
+}
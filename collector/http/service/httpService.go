@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"regexp"
+	"strings"
 	"sync"
 	"time"
 
@@ -40,6 +43,11 @@ func InitHTTPOnStart() {
 	go Request()
 	// 定时任务执行 Request
 	cs.AddCronJob(time.Duration(env.GetServerConfig().Collector.Ping.PingInterval)*time.Hour, Request)
+	// 定时任务执行访问入口探测
+	if interval := env.GetServerConfig().Collector.Request.DiscoveryInterval; interval > 0 {
+		go Discover()
+		cs.AddCronJob(time.Duration(interval)*time.Hour, Discover)
+	}
 
 	fmt.Println("Request 模块初始化结束...")
 }
@@ -64,6 +72,15 @@ func Request() {
 		log.Info("Request 站点列表为空")
 		return
 	}
+	// 加载指纹规则 (文件有更新时重新加载)
+	if ruleErr := loadFingerprintRules(); ruleErr != nil {
+		log.Error(ruleErr.GetMsg())
+	}
+	// 加载服务商数据, 用于按 IP 段 / ASN 识别 CDN (文件有更新时重新加载)
+	if providerErr := cs.LoadProviderRules(); providerErr != nil {
+		log.Error(providerErr.GetMsg())
+	}
+
 	log.Info("HTTP 采集开始")
 	// 遍历站点列表, 每个站点开一个线程执行 request
 	for _, v := range requestList {
@@ -109,6 +126,13 @@ func getRequestResult(site models.GfnCollectorDomain) func() {
 			Redirects:     result.Redirects,
 			Headers:       result.Headers,
 			Meta:          result.Meta,
+			Technologies:  result.Technologies,
+			RemoteAddr:    result.RemoteAddr,
+			Hops:          result.Hops,
+			Edges:         result.Edges,
+			Challenge:     result.Challenge,
+			Label:         result.Label,
+			LabelReasons:  result.LabelReasons,
 			TLSVersion:    result.TLSVersion,
 			CipherSuite:   result.CipherSuite,
 			CertExpiry:    result.CertExpiry.String(),
@@ -136,11 +160,21 @@ func getRequestResult(site models.GfnCollectorDomain) func() {
 			Info:       string(jsonResult),
 			CreateTime: result.StartTime,
 		}
+		// 最后一跳的连接 IP, 便于与 DNS 记录关联
+		if n := len(result.Hops); n > 0 && result.Hops[n-1].IP != "" {
+			httpSaveRecord.RemoteIP = &result.Hops[n-1].IP
+		}
+		if result.Label != "" {
+			httpSaveRecord.Label = &result.Label
+		}
 
 		if httpRecord.StatusCode == 0 || jsonResult == nil {
-			httpSaveRecord.Status = "failure"
+			httpSaveRecord.Status = models.HTTPStatusFailure
+		} else if httpRecord.Challenge != "" {
+			// 质询页 / 拦截页不算成功
+			httpSaveRecord.Status = models.HTTPStatusChallenge
 		} else {
-			httpSaveRecord.Status = "success"
+			httpSaveRecord.Status = models.HTTPStatusSuccess
 		}
 
 		// 记录存redis
@@ -173,6 +207,10 @@ func performRequest(site models.GfnCollectorDomain) (res models.HTTPModel) {
 	res.Meta = make(map[string]string)
 	res.Headers = make(map[string][]string)
 	res.Redirects = []string{}
+	res.Technologies = []models.Technology{}
+	res.Edges = []models.EdgeProvider{}
+	res.Hops = []models.HopModel{}
+	res.LabelReasons = []string{}
 
 	transport := &http.Transport{
 		TLSClientConfig: &tls.Config{
@@ -186,11 +224,13 @@ func performRequest(site models.GfnCollectorDomain) (res models.HTTPModel) {
 	}
 
 	redirects := []string{}
+	hopURL := res.Url // 当前跳对应的 url
 	client := &http.Client{
 		Transport: transport,
 		Timeout:   25 * time.Second,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			redirects = append(redirects, req.URL.String())
+			hopURL = req.URL.String()
 			return nil
 		},
 	}
@@ -205,6 +245,14 @@ func performRequest(site models.GfnCollectorDomain) (res models.HTTPModel) {
 	for k, v := range models.HeadersMap {
 		req.Header.Set(k, v)
 	}
+	// 记录每一跳实际连接的地址
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			res.RemoteAddr = info.Conn.RemoteAddr().String()
+			res.Hops = append(res.Hops, buildHop(hopURL, res.RemoteAddr, site.Proxy == "1", info.Reused))
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
 
 	// 请求开始
 	start := time.Now()
@@ -258,8 +306,25 @@ func performRequest(site models.GfnCollectorDomain) (res models.HTTPModel) {
 		if m := reKeywords.FindSubmatch(body); len(m) > 1 {
 			res.Meta["keywords"] = string(m[1])
 		}
+
+		// 识别站点技术栈
+		res.Technologies = detectTechnologies(resp.Header, resp.Cookies(), body)
+		// 识别质询页 / 拦截页
+		res.Challenge = detectChallenge(resp.Header, res.Title, body)
+		// 识别停放 / 出售 / 暂停 / 默认页 / soft-404
+		nameservers := getSiteNameservers(strings.TrimPrefix(strings.TrimPrefix(res.Url, "https://"), "http://"), site.Name)
+		res.Label, res.LabelReasons = classifySite(&res, body, nameservers)
 	}
 
+	// 识别 CDN / WAF, 走代理时连接地址是代理, 不参与 IP 段匹配
+	var remoteIP net.IP
+	if site.Proxy != "1" {
+		if host, _, splitErr := net.SplitHostPort(res.RemoteAddr); splitErr == nil {
+			remoteIP = net.ParseIP(host)
+		}
+	}
+	res.Edges = detectEdges(resp.Header, resp.Cookies(), remoteIP)
+
 	// TLS 证书检查
 	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
 		cert := resp.TLS.PeerCertificates[0]                             // 服务器证书
@@ -287,3 +352,29 @@ func performRequest(site models.GfnCollectorDomain) (res models.HTTPModel) {
 
 	return
 }
+
+// 构建单跳连接信息, 非代理时补充 IP 归属地
+func buildHop(hopURL string, remoteAddr string, viaProxy bool, reused bool) models.HopModel {
+	hop := models.HopModel{
+		Url:        hopURL,
+		RemoteAddr: remoteAddr,
+		ViaProxy:   viaProxy,
+		Reused:     reused,
+	}
+	// 走代理时连接的是代理服务器, 无法得知目标 IP
+	if viaProxy {
+		return hop
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return hop
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return hop
+	}
+	hop.IP = ip.String()
+	geo := cs.LookupGeoIP(ip)
+	hop.Country, hop.City, hop.ASN, hop.ISP = geo.Country, geo.City, geo.ASN, geo.ISP
+	return hop
+}
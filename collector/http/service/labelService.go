@@ -0,0 +1,122 @@
+package service
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/http/models"
+	cs "github.com/GoFurry/gofurry-nav-collector/common/service"
+)
+
+// 编译后的站点状态规则
+type labelMatcher struct {
+	label string
+	title []*regexp.Regexp
+	body  []*regexp.Regexp
+}
+
+var labelMatchers = compileLabelRules()
+
+// ============== HTTP模块 - 站点状态识别 ==============
+
+func compileLabelRules() []labelMatcher {
+	matchers := make([]labelMatcher, 0, len(models.LabelRules))
+	for _, rule := range models.LabelRules {
+		m := labelMatcher{label: rule.Label}
+		for _, v := range rule.Title {
+			m.title = append(m.title, regexp.MustCompile("(?i)"+v))
+		}
+		for _, v := range rule.Body {
+			m.body = append(m.body, regexp.MustCompile("(?i)"+v))
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers
+}
+
+// 读取 DNS 模块采集的 NS 记录
+func getSiteNameservers(siteName string, domain string) []string {
+	var res []string
+	for _, key := range []string{"dns:" + siteName, "dns:" + domain} {
+		data, err := cs.HGet(key, "NS")
+		if err != nil || data == "" {
+			continue
+		}
+		var records []struct {
+			Value string `json:"value"`
+		}
+		if jsonErr := json.Unmarshal([]byte(data), &records); jsonErr != nil {
+			continue
+		}
+		for _, r := range records {
+			res = append(res, strings.TrimSuffix(strings.ToLower(r.Value), "."))
+		}
+		break
+	}
+	return res
+}
+
+// 综合停放商 NS、跳转目标、标题和页面内容判断站点状态
+func classifySite(res *models.HTTPModel, body []byte, nameservers []string) (string, []string) {
+	var reasons []string
+
+	// 跳转到停放 / 出售平台
+	finalURL := res.Url
+	if n := len(res.Redirects); n > 0 {
+		finalURL = res.Redirects[n-1]
+	}
+	if u, err := url.Parse(finalURL); err == nil {
+		host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+		domain := strings.TrimSuffix(strings.ToLower(res.Domain), ".")
+		switch {
+		case host == models.ParkingRedirectPrefix+domain:
+			return models.SiteLabelParked, append(reasons, "redirect to "+finalURL)
+		case strings.HasSuffix(strings.ToLower(u.Path), models.SuspendedPagePath):
+			return models.SiteLabelSuspended, append(reasons, "redirect to "+finalURL)
+		case !hostWithin(host, domain):
+			// 只关注跳出本域名的情况
+			for _, target := range models.ParkingRedirectHosts {
+				if hostWithin(host, target.Host) {
+					return target.Label, append(reasons, "redirect to "+finalURL)
+				}
+			}
+		}
+	}
+
+	// 页面规则
+	title := strings.TrimSpace(res.Title)
+	for _, m := range labelMatchers {
+		// 真实的 404 不算 soft-404
+		if m.label == models.SiteLabelSoft404 && res.StatusCode >= 300 {
+			continue
+		}
+		for _, re := range m.title {
+			if title != "" && re.MatchString(title) {
+				return m.label, append(reasons, "title: "+title)
+			}
+		}
+		for _, re := range m.body {
+			if loc := re.FindIndex(body); loc != nil {
+				return m.label, append(reasons, "body: "+string(body[loc[0]:loc[1]]))
+			}
+		}
+	}
+
+	// 停放商 NS, 页面无明显特征时也视为停放
+	for _, ns := range nameservers {
+		for _, suffix := range models.ParkingNameservers {
+			if ns == suffix || strings.HasSuffix(ns, "."+suffix) {
+				return models.SiteLabelParked, append(reasons, "nameserver "+ns)
+			}
+		}
+	}
+
+	return models.SiteLabelLive, reasons
+}
+
+// host 是否为 domain 本身或其子域名
+func hostWithin(host string, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,54 @@
+package dao
+
+import (
+	"github.com/GoFurry/gofurry-nav-collector/collector/whois/models"
+	"github.com/GoFurry/gofurry-nav-collector/common"
+	"github.com/GoFurry/gofurry-nav-collector/common/abstract"
+)
+
+var newWhoisDao = new(whoisDao)
+
+func init() {
+	newWhoisDao.Init()
+}
+
+type whoisDao struct{ abstract.Dao }
+
+func GetWhoisDao() *whoisDao { return newWhoisDao }
+
+func (dao whoisDao) GetList() ([]models.GfnCollectorDomain, common.GFError) {
+	var res []models.GfnCollectorDomain
+	db := dao.Gm.Table(models.TableNameGfnCollectorDomain)
+	db.Find(&res)
+	if err := db.Error; err != nil {
+		return nil, common.NewDaoError(err.Error())
+	}
+	return res, nil
+}
+
+// 保留 count 条注册信息历史记录
+func (dao whoisDao) DeleteByNum(count string) (int64, common.GFError) {
+	sql := `
+		DELETE FROM ` + models.TableNameGfnCollectorLogWhois + `
+		WHERE id NOT IN (
+		  SELECT id
+		  FROM (
+			SELECT 
+			  id,
+			  ROW_NUMBER() OVER (
+				PARTITION BY name 
+				ORDER BY create_time DESC
+			  ) AS rn
+			FROM ` + models.TableNameGfnCollectorLogWhois + `
+		  ) AS ranked
+		  WHERE rn <= ?
+		);`
+
+	db := dao.Gm.Table(models.TableNameGfnCollectorLogWhois)
+	result := db.Exec(sql, count)
+	if err := db.Error; err != nil {
+		return result.RowsAffected, common.NewDaoError(err.Error())
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+const TableNameGfnCollectorDomain = "gfn_collector_domain"
+
+// GfnCollectorDomain mapped from table <gfn_collector_domain>
+type GfnCollectorDomain struct {
+	ID     int64   `gorm:"column:id;type:bigint;primaryKey;comment:域名请求表id" json:"id"`                        // 域名请求表id
+	Name   string  `gorm:"column:name;type:character varying(255);not null;comment:域名" json:"name"`           // 域名
+	Proxy  string  `gorm:"column:proxy;type:character varying(4);not null;comment:是否需要代理加速 1 0" json:"proxy"` // 是否需要代理加速 1 0
+	Prefix *string `gorm:"column:prefix;type:character varying(255);comment:是否有前缀" json:"prefix"`             // 是否有前缀
+	TLS    string  `gorm:"column:tls;type:character varying(4);not null;comment:是否 https 1 0" json:"tls"`     // 是否 https 1 0
+}
+
+// TableName GfnCollectorDomain's table name
+func (*GfnCollectorDomain) TableName() string {
+	return TableNameGfnCollectorDomain
+}
+
+const TableNameGfnCollectorLogWhois = "gfn_collector_log_whois"
+
+// GfnCollectorLogWhois mapped from table <gfn_collector_log_whois>
+type GfnCollectorLogWhois struct {
+	ID           int64      `gorm:"column:id;type:bigint;primaryKey;comment:注册信息日志表 id" json:"id"`                                            // 注册信息日志表 id
+	Name         string     `gorm:"column:name;type:character varying(255);not null;comment:可注册域名" json:"name"`                               // 可注册域名
+	Source       string     `gorm:"column:source;type:character varying(20);not null;comment:查询来源 rdap whois" json:"source"`                  // 查询来源 rdap whois
+	Registrar    *string    `gorm:"column:registrar;type:character varying(255);comment:注册商" json:"registrar"`                                // 注册商
+	CreatedDate  *time.Time `gorm:"column:created_date;type:timestamp;comment:注册时间" json:"createdDate"`                                       // 注册时间
+	ExpiresDate  *time.Time `gorm:"column:expires_date;type:timestamp;comment:到期时间" json:"expiresDate"`                                       // 到期时间
+	DaysLeft     *int       `gorm:"column:days_left;type:int;comment:剩余天数" json:"daysLeft"`                                                   // 剩余天数
+	Expiry       string     `gorm:"column:expiry;type:character varying(20);not null;comment:到期状态 ok expiring expired unknown" json:"expiry"` // 到期状态 ok expiring expired unknown
+	Registration *string    `gorm:"column:registration;type:json;comment:注册信息" json:"registration"`                                           // 注册信息
+	Status       string     `gorm:"column:status;type:character varying(20);not null;comment:采集状态 success failure notfound" json:"status"`    // 采集状态 success failure notfound
+	CreateTime   time.Time  `gorm:"column:create_time;type:int;type:unsigned;not null;autoCreateTime;comment:采集时间" json:"createTime"`         // 采集时间
+}
+
+// TableName GfnCollectorLogWhois's table name
+func (*GfnCollectorLogWhois) TableName() string {
+	return TableNameGfnCollectorLogWhois
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// 查询来源
+const (
+	SourceRDAP  = "rdap"
+	SourceWHOIS = "whois"
+)
+
+// 采集状态
+const (
+	WhoisStatusSuccess  = "success"
+	WhoisStatusFailure  = "failure"
+	WhoisStatusNotFound = "notfound"
+)
+
+// 到期状态
+const (
+	ExpiryOK       = "ok"
+	ExpiryExpiring = "expiring"
+	ExpiryExpired  = "expired"
+	ExpiryUnknown  = "unknown"
+)
+
+// 表示域名已停止解析或即将被删除的状态码, 统一为小写去空格后匹配
+var HoldStatuses = map[string]bool{
+	"clienthold":       true,
+	"serverhold":       true,
+	"redemptionperiod": true,
+	"pendingdelete":    true,
+	"pendingrestore":   true,
+	"inactive":         true,
+}
+
+// Registration 域名注册信息
+type Registration struct {
+	Domain      string     `json:"domain"`       // 可注册域名
+	Source      string     `json:"source"`       // rdap / whois
+	Server      string     `json:"server"`       // 查询的服务地址
+	Registrar   string     `json:"registrar"`    // 注册商
+	RegistrarID string     `json:"registrar_id"` // 注册商 IANA ID
+	Created     *time.Time `json:"created"`      // 注册时间
+	Updated     *time.Time `json:"updated"`      // 最后更新时间
+	Expires     *time.Time `json:"expires"`      // 到期时间
+	DaysLeft    *int       `json:"days_left"`    // 剩余天数, 已过期时为负数
+	Expiry      string     `json:"expiry"`       // 到期状态 ok expiring expired unknown
+	Status      []string   `json:"status"`       // 注册局状态码
+	Nameservers []string   `json:"nameservers"`  // 注册局登记的 NS
+	Warnings    []string   `json:"warnings"`     // 告警
+	Error       string     `json:"error"`        // 查询失败原因
+}
+
+// RDAPBootstrap IANA RDAP 引导文件 (RFC 9224)
+type RDAPBootstrap struct {
+	Version  string       `json:"version"`
+	Services [][][]string `json:"services"` // [[顶级域...], [服务地址...]]
+}
+
+// RDAPDomain RDAP 域名对象中用到的字段 (RFC 9083)
+type RDAPDomain struct {
+	LdhName     string           `json:"ldhName"`
+	Status      []string         `json:"status"`
+	Events      []RDAPEvent      `json:"events"`
+	Entities    []RDAPEntity     `json:"entities"`
+	Nameservers []RDAPNameserver `json:"nameservers"`
+}
+
+type RDAPEvent struct {
+	Action string `json:"eventAction"`
+	Date   string `json:"eventDate"`
+}
+
+type RDAPEntity struct {
+	Roles      []string       `json:"roles"`
+	VcardArray []any          `json:"vcardArray"`
+	PublicIds  []RDAPPublicID `json:"publicIds"`
+}
+
+type RDAPPublicID struct {
+	Type       string `json:"type"`
+	Identifier string `json:"identifier"`
+}
+
+type RDAPNameserver struct {
+	LdhName string `json:"ldhName"`
+}
@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/whois/models"
+)
+
+// ============== 注册信息 - RDAP 解析 ==============
+
+// 解析引导文件, 返回顶级域 -> 服务地址, 同一顶级域有多个地址时优先 https
+func ParseRDAPBootstrap(body []byte) (map[string]string, error) {
+	var bootstrap models.RDAPBootstrap
+	if err := json.Unmarshal(body, &bootstrap); err != nil {
+		return nil, err
+	}
+
+	servers := make(map[string]string)
+	for _, service := range bootstrap.Services {
+		if len(service) < 2 || len(service[1]) == 0 {
+			continue
+		}
+		server := service[1][0]
+		for _, u := range service[1] {
+			if strings.HasPrefix(u, "https://") {
+				server = u
+				break
+			}
+		}
+		for _, tld := range service[0] {
+			servers[strings.ToLower(tld)] = server
+		}
+	}
+	return servers, nil
+}
+
+// 用 RDAP 域名对象填充注册信息
+func FillRDAP(reg *models.Registration, body []byte) error {
+	var obj models.RDAPDomain
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return err
+	}
+
+	for _, event := range obj.Events {
+		switch strings.ToLower(event.Action) {
+		case "registration":
+			reg.Created = ParseRegistryTime(event.Date)
+		case "expiration":
+			reg.Expires = ParseRegistryTime(event.Date)
+		case "last changed":
+			reg.Updated = ParseRegistryTime(event.Date)
+		}
+	}
+	reg.Status = obj.Status
+	for _, ns := range obj.Nameservers {
+		if ns.LdhName != "" {
+			reg.Nameservers = append(reg.Nameservers, strings.TrimSuffix(strings.ToLower(ns.LdhName), "."))
+		}
+	}
+	for _, entity := range obj.Entities {
+		if !hasRole(entity.Roles, "registrar") {
+			continue
+		}
+		reg.Registrar = vcardName(entity.VcardArray)
+		for _, id := range entity.PublicIds {
+			if strings.EqualFold(id.Type, "IANA Registrar ID") {
+				reg.RegistrarID = id.Identifier
+			}
+		}
+	}
+	return nil
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if strings.EqualFold(r, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// 从 jCard 中取 fn, 格式为 ["vcard", [["fn", {}, "text", "名称"], ...]]
+func vcardName(vcard []any) string {
+	if len(vcard) < 2 {
+		return ""
+	}
+	props, ok := vcard[1].([]any)
+	if !ok {
+		return ""
+	}
+	for _, p := range props {
+		prop, ok := p.([]any)
+		if !ok || len(prop) < 4 {
+			continue
+		}
+		if name, _ := prop[0].(string); name == "fn" {
+			value, _ := prop[3].(string)
+			return value
+		}
+	}
+	return ""
+}
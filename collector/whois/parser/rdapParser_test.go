@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/whois/models"
+)
+
+func TestParseRDAPBootstrap(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "https preferred",
+			body: `{"version":"1.0","services":[
+				[["com","NET"],["http://rdap.verisign.com/com/v1/","https://rdap.verisign.com/com/v1/"]],
+				[["org"],["https://rdap.publicinterestregistry.org/rdap/"]]
+			]}`,
+			want: map[string]string{
+				"com": "https://rdap.verisign.com/com/v1/",
+				"net": "https://rdap.verisign.com/com/v1/",
+				"org": "https://rdap.publicinterestregistry.org/rdap/",
+			},
+		},
+		{
+			name: "only http",
+			body: `{"services":[[["example"],["http://rdap.example/"]]]}`,
+			want: map[string]string{"example": "http://rdap.example/"},
+		},
+		{
+			name: "malformed services skipped",
+			body: `{"services":[[["a"]],[["b"],[]],[["c"],["https://rdap.c/"]]]}`,
+			want: map[string]string{"c": "https://rdap.c/"},
+		},
+		{
+			name:    "invalid json",
+			body:    `<html>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRDAPBootstrap([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRDAPBootstrap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRDAPBootstrap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFillRDAP(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    models.Registration
+		wantErr bool
+	}{
+		{
+			name: "full domain object",
+			body: `{
+				"objectClassName": "domain",
+				"ldhName": "EXAMPLE.COM",
+				"status": ["client delete prohibited", "client transfer prohibited"],
+				"events": [
+					{"eventAction": "registration", "eventDate": "1995-08-14T04:00:00Z"},
+					{"eventAction": "Expiration", "eventDate": "2025-08-13T04:00:00Z"},
+					{"eventAction": "last changed", "eventDate": "2024-08-14T07:01:34Z"},
+					{"eventAction": "last update of RDAP database", "eventDate": "2024-09-01T00:00:00Z"}
+				],
+				"entities": [
+					{"roles": ["technical"], "vcardArray": ["vcard", [["fn", {}, "text", "Tech Contact"]]]},
+					{
+						"roles": ["Registrar"],
+						"publicIds": [{"type": "IANA Registrar ID", "identifier": "376"}],
+						"vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "Example Registrar"]]]
+					}
+				],
+				"nameservers": [{"ldhName": "A.IANA-SERVERS.NET."}, {"ldhName": ""}, {"ldhName": "b.iana-servers.net"}]
+			}`,
+			want: models.Registration{
+				Registrar:   "Example Registrar",
+				RegistrarID: "376",
+				Created:     utc(1995, 8, 14, 4),
+				Updated:     utc(2024, 8, 14, 7, 1, 34),
+				Expires:     utc(2025, 8, 13, 4),
+				Status:      []string{"client delete prohibited", "client transfer prohibited"},
+				Nameservers: []string{"a.iana-servers.net", "b.iana-servers.net"},
+			},
+		},
+		{
+			name: "malformed vcard",
+			body: `{"entities": [{"roles": ["registrar"], "vcardArray": ["vcard", "fn"]}]}`,
+			want: models.Registration{},
+		},
+		{
+			name: "no events",
+			body: `{"ldhName": "example.org", "status": ["active"]}`,
+			want: models.Registration{Status: []string{"active"}},
+		},
+		{
+			name:    "invalid json",
+			body:    `{"events": [`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got models.Registration
+			err := FillRDAP(&got, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FillRDAP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FillRDAP() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
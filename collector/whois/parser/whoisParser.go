@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"strings"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/whois/models"
+)
+
+// 应答解析不依赖配置和数据库, 便于单独测试
+
+// 表示域名不存在的应答片段
+var whoisNotFound = []string{
+	"no match", "not found", "no entries found", "no data found", "no object found",
+	"status: free", "status: available", "is available for registration", "domain not registered",
+}
+
+// 各字段在不同注册局中的写法, 统一小写后匹配
+var (
+	whoisRegistrarKeys   = []string{"registrar", "sponsoring registrar", "registrar name", "registrar organization"}
+	whoisRegistrarIDKeys = []string{"registrar iana id"}
+	whoisCreatedKeys     = []string{"creation date", "created", "created on", "registered", "registered on", "registration time", "domain registration date", "domain record activated"}
+	whoisExpiresKeys     = []string{"registry expiry date", "registrar registration expiration date", "expiry date", "expiration date", "expiration time", "expires", "expires on", "expire", "paid-till", "renewal date", "domain expiration date"}
+	whoisUpdatedKeys     = []string{"updated date", "last updated", "last modified", "changed", "modified"}
+	whoisStatusKeys      = []string{"domain status", "status", "state"}
+	whoisNameserverKeys  = []string{"name server", "name servers", "nameserver", "nameservers", "nserver", "dns"}
+)
+
+// ============== 注册信息 - WHOIS 解析 ==============
+
+// 应答中是否有域名不存在的提示
+func WhoisIsNotFound(text string) bool {
+	lower := strings.ToLower(text)
+	for _, pattern := range whoisNotFound {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// 解析 key: value 格式的应答, 值为空时后续缩进的行都属于该 key
+func ParseWhois(text string) map[string][]string {
+	fields := make(map[string][]string)
+	current := ""
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r", ""), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			current = ""
+			continue
+		}
+		if strings.HasPrefix(trimmed, "%") || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ">>>") {
+			continue
+		}
+		// 上一个 key 的续行
+		if current != "" && (line[0] == ' ' || line[0] == '\t') && !strings.Contains(trimmed, ": ") {
+			fields[current] = append(fields[current], trimmed)
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			current = ""
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if value == "" {
+			current = key
+			continue
+		}
+		current = ""
+		fields[key] = append(fields[key], value)
+	}
+	return fields
+}
+
+// 按字段别名填充注册信息, 已有的值不覆盖
+func FillRegistration(reg *models.Registration, fields map[string][]string) {
+	if reg.Registrar == "" {
+		reg.Registrar = FirstField(fields, whoisRegistrarKeys...)
+	}
+	if reg.RegistrarID == "" {
+		reg.RegistrarID = FirstField(fields, whoisRegistrarIDKeys...)
+	}
+	if reg.Created == nil {
+		reg.Created = ParseRegistryTime(FirstField(fields, whoisCreatedKeys...))
+	}
+	if reg.Expires == nil {
+		reg.Expires = ParseRegistryTime(FirstField(fields, whoisExpiresKeys...))
+	}
+	if reg.Updated == nil {
+		reg.Updated = ParseRegistryTime(FirstField(fields, whoisUpdatedKeys...))
+	}
+	if len(reg.Status) == 0 {
+		// 状态后可能带说明链接, 如 "clientTransferProhibited https://icann.org/epp#..."
+		for _, value := range allFields(fields, whoisStatusKeys...) {
+			if status := strings.Fields(value); len(status) > 0 {
+				reg.Status = append(reg.Status, status[0])
+			}
+		}
+	}
+	if len(reg.Nameservers) == 0 {
+		seen := make(map[string]struct{})
+		for _, value := range allFields(fields, whoisNameserverKeys...) {
+			ns := strings.Fields(value)
+			if len(ns) == 0 {
+				continue
+			}
+			name := strings.TrimSuffix(strings.ToLower(ns[0]), ".")
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				reg.Nameservers = append(reg.Nameservers, name)
+			}
+		}
+	}
+}
+
+// 按顺序取第一个存在的字段
+func FirstField(fields map[string][]string, keys ...string) string {
+	for _, key := range keys {
+		if values := fields[key]; len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// 取第一个存在的字段的全部值
+func allFields(fields map[string][]string, keys ...string) []string {
+	for _, key := range keys {
+		if values := fields[key]; len(values) > 0 {
+			return values
+		}
+	}
+	return nil
+}
+
+// 解析注册局返回的各种时间格式
+func ParseRegistryTime(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	// 部分注册局在时间后附带说明, 如 "2025-01-01 (YYYY-MM-DD)"
+	if i := strings.Index(value, " ("); i > 0 {
+		value = value[:i]
+	}
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04:05Z0700",
+		"2006-01-02 15:04:05Z07:00",
+		"2006-01-02 15:04:05 MST",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+		"2006.01.02 15:04:05",
+		"2006.01.02",
+		"2006/01/02 15:04:05",
+		"2006/01/02",
+		"02-Jan-2006 15:04:05 MST",
+		"02-Jan-2006",
+		"02.01.2006 15:04:05",
+		"02.01.2006",
+		"January 02 2006",
+		"Mon Jan 2 15:04:05 MST 2006",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
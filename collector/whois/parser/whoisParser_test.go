@@ -0,0 +1,223 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/whois/models"
+)
+
+// gTLD 注册局精简应答
+const verisignReply = `   Domain Name: EXAMPLE.COM
+   Registry Domain ID: 2336799_DOMAIN_COM-VRSN
+   Registrar WHOIS Server: whois.iana.org
+   Updated Date: 2024-08-14T07:01:34Z
+   Creation Date: 1995-08-14T04:00:00Z
+   Registry Expiry Date: 2025-08-13T04:00:00Z
+   Registrar: RESERVED-Internet Assigned Numbers Authority
+   Registrar IANA ID: 376
+   Domain Status: clientDeleteProhibited https://icann.org/epp#clientDeleteProhibited
+   Domain Status: clientTransferProhibited https://icann.org/epp#clientTransferProhibited
+   Name Server: A.IANA-SERVERS.NET
+   Name Server: B.IANA-SERVERS.NET
+>>> Last update of whois database: 2024-09-01T00:00:00Z <<<
+`
+
+// 值在下一行缩进给出的格式
+const nominetReply = "\r\n    Domain name:\r\n        example.co.uk\r\n\r\n    Registrar:\r\n        Example Registrar Ltd [Tag = EXAMPLE]\r\n\r\n" +
+	"    Relevant dates:\r\n        Registered on: 26-Aug-1996\r\n        Expiry date:  01-Sep-2026\r\n\r\n" +
+	"    Name servers:\r\n        ns1.example.net.\r\n        ns2.example.net\r\n        NS1.EXAMPLE.NET\r\n"
+
+// 带注释和大小写混用的格式
+const denicReply = `% Restricted rights.
+%
+Domain: example.de
+Nserver: ns1.example.de. 192.0.2.1
+Nserver: ns2.example.de
+Status: connect
+Changed: 2023-03-01T10:00:00+01:00
+`
+
+func TestParseWhois(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want map[string][]string
+	}{
+		{
+			name: "gTLD",
+			text: "Domain Name: EXAMPLE.COM\nName Server: A.IANA-SERVERS.NET\nName Server: B.IANA-SERVERS.NET\n",
+			want: map[string][]string{
+				"domain name": {"EXAMPLE.COM"},
+				"name server": {"A.IANA-SERVERS.NET", "B.IANA-SERVERS.NET"},
+			},
+		},
+		{
+			name: "continuation lines",
+			text: "Name servers:\n    ns1.example.net\n    ns2.example.net\n\nRegistrar:\n    Example Ltd\n",
+			want: map[string][]string{
+				"name servers": {"ns1.example.net", "ns2.example.net"},
+				"registrar":    {"Example Ltd"},
+			},
+		},
+		{
+			name: "comments and banners skipped",
+			text: "% comment: ignored\n# another: ignored\n>>> Last update: now <<<\nStatus: ok\n",
+			want: map[string][]string{"status": {"ok"}},
+		},
+		{
+			name: "value containing colon",
+			text: "Updated Date: 2024-08-14T07:01:34Z\n",
+			want: map[string][]string{"updated date": {"2024-08-14T07:01:34Z"}},
+		},
+		{
+			name: "indented key value is not a continuation",
+			text: "Registrar:\n    Name: Example Ltd\n",
+			want: map[string][]string{"name": {"Example Ltd"}},
+		},
+		{
+			name: "line without key",
+			text: "free text line\nDomain: example.org\n",
+			want: map[string][]string{"domain": {"example.org"}},
+		},
+		{
+			name: "empty",
+			text: "",
+			want: map[string][]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseWhois(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseWhois() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFillRegistration(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		base models.Registration
+		want models.Registration
+	}{
+		{
+			name: "verisign",
+			text: verisignReply,
+			want: models.Registration{
+				Registrar:   "RESERVED-Internet Assigned Numbers Authority",
+				RegistrarID: "376",
+				Created:     utc(1995, 8, 14, 4),
+				Updated:     utc(2024, 8, 14, 7, 1, 34),
+				Expires:     utc(2025, 8, 13, 4),
+				Status:      []string{"clientDeleteProhibited", "clientTransferProhibited"},
+				Nameservers: []string{"a.iana-servers.net", "b.iana-servers.net"},
+			},
+		},
+		{
+			name: "nominet",
+			text: nominetReply,
+			want: models.Registration{
+				Registrar:   "Example Registrar Ltd [Tag = EXAMPLE]",
+				Created:     utc(1996, 8, 26),
+				Expires:     utc(2026, 9, 1),
+				Nameservers: []string{"ns1.example.net", "ns2.example.net"},
+			},
+		},
+		{
+			name: "denic",
+			text: denicReply,
+			want: models.Registration{
+				Updated:     utc(2023, 3, 1, 9),
+				Status:      []string{"connect"},
+				Nameservers: []string{"ns1.example.de", "ns2.example.de"},
+			},
+		},
+		{
+			name: "existing values kept",
+			text: verisignReply,
+			base: models.Registration{
+				Registrar:   "From Registry",
+				Expires:     utc(2030, 1, 1),
+				Nameservers: []string{"ns.example.org"},
+			},
+			want: models.Registration{
+				Registrar:   "From Registry",
+				RegistrarID: "376",
+				Created:     utc(1995, 8, 14, 4),
+				Updated:     utc(2024, 8, 14, 7, 1, 34),
+				Expires:     utc(2030, 1, 1),
+				Status:      []string{"clientDeleteProhibited", "clientTransferProhibited"},
+				Nameservers: []string{"ns.example.org"},
+			},
+		},
+		{
+			name: "unparseable date",
+			text: "Expiry Date: sometime next year\n",
+			want: models.Registration{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.base
+			FillRegistration(&got, ParseWhois(tt.text))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FillRegistration() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWhoisIsNotFound(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"No match for \"NOSUCH.COM\".", true},
+		{"Status: free", true},
+		{"The queried object does not exist: NOT FOUND", true},
+		{verisignReply, false},
+	}
+	for _, tt := range tests {
+		if got := WhoisIsNotFound(tt.text); got != tt.want {
+			t.Errorf("WhoisIsNotFound(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseRegistryTime(t *testing.T) {
+	tests := []struct {
+		value string
+		want  *time.Time
+	}{
+		{"2025-08-13T04:00:00Z", utc(2025, 8, 13, 4)},
+		{"2025-08-13T04:00:00.000Z", utc(2025, 8, 13, 4)},
+		{"2025-08-13T12:00:00+08:00", utc(2025, 8, 13, 4)},
+		{"2025-08-13T04:00:00", utc(2025, 8, 13, 4)},
+		{"2025-08-13 04:00:00", utc(2025, 8, 13, 4)},
+		{"2025-08-13 (YYYY-MM-DD)", utc(2025, 8, 13)},
+		{"2025.08.13", utc(2025, 8, 13)},
+		{"2025/08/13 04:00:00", utc(2025, 8, 13, 4)},
+		{"13-Aug-2025", utc(2025, 8, 13)},
+		{"13.08.2025", utc(2025, 8, 13)},
+		{"  2025-08-13  ", utc(2025, 8, 13)},
+		{"", nil},
+		{"never", nil},
+	}
+	for _, tt := range tests {
+		got := ParseRegistryTime(tt.value)
+		if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+			t.Errorf("ParseRegistryTime(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+// 构造 UTC 时间, 依次为年月日时分秒
+func utc(year int, month time.Month, day int, clock ...int) *time.Time {
+	hms := make([]int, 3)
+	copy(hms, clock)
+	t := time.Date(year, month, day, hms[0], hms[1], hms[2], 0, time.UTC)
+	return &t
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/whois/models"
+	"github.com/GoFurry/gofurry-nav-collector/collector/whois/parser"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+)
+
+const (
+	// WHOIS 应答大小上限
+	whoisResponseLimit = 256 << 10
+	// 引导服务器默认地址
+	defaultWhoisRefer = "whois.iana.org:43"
+)
+
+// 顶级域 -> WHOIS 服务器, 来自引导服务器的 refer
+var whoisServerCache sync.Map
+
+// ============== 注册信息 - WHOIS ==============
+
+// 通过 43 端口查询, 注册局给出注册商 WHOIS 时再查一次补全缺失字段
+func queryWhois(domain string, tld string) (models.Registration, error) {
+	reg := models.Registration{Domain: domain, Source: models.SourceWHOIS}
+	server, err := whoisServer(tld)
+	if err != nil {
+		return reg, err
+	}
+	reg.Server = server
+
+	text, err := whoisQuery(server, domain)
+	if err != nil {
+		return reg, err
+	}
+	fields := parser.ParseWhois(text)
+	parser.FillRegistration(&reg, fields)
+	// 不存在的提示各注册局写法不一, 只在没有解析到任何信息时判断, 避免误判免责声明
+	if reg.Expires == nil && reg.Registrar == "" && parser.WhoisIsNotFound(text) {
+		return reg, errDomainNotFound
+	}
+
+	// 精简模式的注册局只返回部分字段
+	if referral := parser.FirstField(fields, "registrar whois server"); referral != "" && (reg.Expires == nil || reg.Registrar == "") {
+		referral = whoisAddress(strings.TrimPrefix(strings.TrimPrefix(referral, "whois://"), "rwhois://"))
+		if referral != server {
+			if text, err = whoisQuery(referral, domain); err == nil && !parser.WhoisIsNotFound(text) {
+				parser.FillRegistration(&reg, parser.ParseWhois(text))
+			}
+		}
+	}
+	return reg, nil
+}
+
+// 查找顶级域的 WHOIS 服务器, 配置优先, 其次向引导服务器查询 refer
+func whoisServer(tld string) (string, error) {
+	if server := env.GetServerConfig().Collector.Whois.WhoisServers[tld]; server != "" {
+		return whoisAddress(server), nil
+	}
+	if cached, ok := whoisServerCache.Load(tld); ok {
+		return cached.(string), nil
+	}
+
+	refer := env.GetServerConfig().Collector.Whois.WhoisRefer
+	if refer == "" {
+		refer = defaultWhoisRefer
+	}
+	text, err := whoisQuery(whoisAddress(refer), tld)
+	if err != nil {
+		return "", err
+	}
+	fields := parser.ParseWhois(text)
+	server := parser.FirstField(fields, "refer")
+	if server == "" {
+		server = parser.FirstField(fields, "whois")
+	}
+	if server == "" {
+		return "", errors.New("no whois server for ." + tld)
+	}
+	server = whoisAddress(server)
+	whoisServerCache.Store(tld, server)
+	return server, nil
+}
+
+// 补全默认端口
+func whoisAddress(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(server, "43")
+}
+
+// 发送查询并读取全部应答
+func whoisQuery(server string, query string) (string, error) {
+	conn, err := net.DialTimeout("tcp", server, whoisTimeout())
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(whoisTimeout()))
+	if _, err = conn.Write([]byte(query + "\r\n")); err != nil {
+		return "", err
+	}
+	body, err := io.ReadAll(io.LimitReader(conn, whoisResponseLimit))
+	if err != nil && len(body) == 0 {
+		return "", err
+	}
+	return string(body), nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/whois/models"
+	"github.com/GoFurry/gofurry-nav-collector/collector/whois/parser"
+	"github.com/GoFurry/gofurry-nav-collector/common/log"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+)
+
+const (
+	// 引导文件刷新间隔
+	rdapBootstrapTTL = 24 * time.Hour
+	// 引导文件加载失败后的重试间隔
+	rdapBootstrapRetry = 10 * time.Minute
+	// 应答大小上限
+	rdapResponseLimit = 1 << 20
+)
+
+// 顶级域 -> RDAP 服务地址
+var rdapBootstrap = struct {
+	sync.Mutex
+	servers map[string]string
+	loaded  time.Time     // 上次加载成功时间
+	failed  time.Time     // 上次加载失败时间
+	loading chan struct{} // 加载中时非空, 加载结束后关闭
+}{}
+
+var rdapClient = initRDAPClient()
+
+// ============== 注册信息 - RDAP ==============
+
+func initRDAPClient() *http.Client {
+	transport := &http.Transport{}
+	if env.GetServerConfig().Collector.Whois.UseProxy {
+		proxyURL, _ := url.Parse(env.GetServerConfig().Collector.Proxy)
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   whoisTimeout(),
+	}
+}
+
+// 查找顶级域的 RDAP 服务, 配置优先, 其次是引导文件
+func rdapServer(tld string) string {
+	if server := env.GetServerConfig().Collector.Whois.RdapServers[tld]; server != "" {
+		return server
+	}
+
+	rdapBootstrap.Lock()
+	done := rdapBootstrap.loading
+	refresh := done == nil && time.Since(rdapBootstrap.loaded) > rdapBootstrapTTL && time.Since(rdapBootstrap.failed) > rdapBootstrapRetry
+	if refresh {
+		done = make(chan struct{})
+		rdapBootstrap.loading = done
+	}
+	servers := rdapBootstrap.servers
+	rdapBootstrap.Unlock()
+
+	switch {
+	case refresh:
+		servers = refreshRDAPBootstrap(done)
+	case servers == nil && done != nil:
+		// 首次加载还没结束时等待结果, 已有旧数据时直接使用, 不排队等下载
+		<-done
+		rdapBootstrap.Lock()
+		servers = rdapBootstrap.servers
+		rdapBootstrap.Unlock()
+	}
+	return servers[tld]
+}
+
+// 在锁外下载引导文件, 失败时继续用旧数据, 重试间隔内不再下载
+func refreshRDAPBootstrap(done chan struct{}) map[string]string {
+	defer close(done)
+	servers, err := loadRDAPBootstrap()
+
+	rdapBootstrap.Lock()
+	defer rdapBootstrap.Unlock()
+	rdapBootstrap.loading = nil
+	if err != nil {
+		log.Error("加载RDAP引导文件失败: ", err)
+		rdapBootstrap.failed = time.Now()
+	} else {
+		rdapBootstrap.servers = servers
+		rdapBootstrap.loaded = time.Now()
+	}
+	return rdapBootstrap.servers
+}
+
+// 下载并解析引导文件
+func loadRDAPBootstrap() (map[string]string, error) {
+	bootstrapURL := env.GetServerConfig().Collector.Whois.RdapBootstrap
+	if bootstrapURL == "" {
+		return map[string]string{}, nil
+	}
+	body, err := rdapGet(bootstrapURL)
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseRDAPBootstrap(body)
+}
+
+// 查询 RDAP 域名对象
+func queryRDAP(domain string, server string) (models.Registration, error) {
+	reg := models.Registration{Domain: domain, Source: models.SourceRDAP, Server: server}
+	body, err := rdapGet(strings.TrimSuffix(server, "/") + "/domain/" + domain)
+	if err != nil {
+		return reg, err
+	}
+	err = parser.FillRDAP(&reg, body)
+	return reg, err
+}
+
+// GET 请求, 404 视为域名不存在
+func rdapGet(target string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rdap+json, application/json")
+	resp, err := rdapClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errDomainNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, rdapResponseLimit))
+}
+
+// 单次查询超时
+func whoisTimeout() time.Duration {
+	if timeout := env.GetServerConfig().Collector.Whois.Timeout; timeout > 0 {
+		return time.Duration(timeout) * time.Second
+	}
+	return 10 * time.Second
+}
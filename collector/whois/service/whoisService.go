@@ -0,0 +1,233 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/collector/whois/dao"
+	"github.com/GoFurry/gofurry-nav-collector/collector/whois/models"
+	"github.com/GoFurry/gofurry-nav-collector/common/log"
+	cs "github.com/GoFurry/gofurry-nav-collector/common/service"
+	"github.com/GoFurry/gofurry-nav-collector/common/util"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"github.com/sourcegraph/conc/pool"
+	"golang.org/x/net/publicsuffix"
+)
+
+// 域名不存在
+var errDomainNotFound = errors.New("domain not found")
+
+// 按可注册域名并行查
+var whoisThread = pool.New().WithMaxGoroutines(env.GetServerConfig().Collector.Whois.WhoisThread)
+var wg sync.WaitGroup
+
+// ============== 注册信息 - 初始化部分 ==============
+
+// 初始化
+func InitWhoisOnStart() {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error(fmt.Sprintf("receive InitWhoisOnStart recover: %v", err))
+		}
+	}()
+	fmt.Println("Whois 模块初始化开始...")
+
+	//初始化后执行一次 ParseWhois
+	go ParseWhois()
+	// 定时任务执行 ParseWhois
+	cs.AddCronJob(time.Duration(env.GetServerConfig().Collector.Whois.WhoisInterval)*time.Hour, ParseWhois)
+
+	fmt.Println("Whois 模块初始化结束...")
+}
+
+// ============== 注册信息 - 执行部分 ==============
+
+// 执行 ParseWhois
+func ParseWhois() {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error(fmt.Sprintf("receive ParseWhois recover: %v", err))
+		}
+	}()
+
+	siteList, err := dao.GetWhoisDao().GetList()
+	if err != nil {
+		log.Error("Whois 获取站点列表失败: " + err.GetMsg())
+		return
+	}
+
+	// 多个站点可能属于同一个可注册域名, 只查一次
+	domains := make(map[string]struct{})
+	for _, site := range siteList {
+		domain, suffixErr := registrableDomain(site.Name)
+		if suffixErr != nil {
+			log.Error(site.Name, " 获取可注册域名失败: ", suffixErr)
+			continue
+		}
+		domains[domain] = struct{}{}
+	}
+	if len(domains) < 1 {
+		log.Info("Whois 站点列表为空")
+		return
+	}
+
+	log.Info("Whois 采集开始")
+	for domain := range domains {
+		wg.Add(1)
+		whoisThread.Go(getWhoisResult(domain))
+	}
+	wg.Wait()
+	log.Info("Whois 采集结束")
+
+	// 每个域名仅保留 100 条注册信息记录
+	count, deleteErr := dao.GetWhoisDao().DeleteByNum(env.GetServerConfig().Collector.Whois.LogCount)
+	if deleteErr != nil {
+		log.Error("删除多余Whois记录失败: ", deleteErr.GetMsg())
+	} else {
+		log.Info("删除多余Whois记录成功, 共删除: ", count)
+	}
+}
+
+func getWhoisResult(domain string) func() {
+	return func() {
+		defer func() {
+			if err := recover(); err != nil {
+				log.Error(fmt.Sprintf("receive WhoisThread recover: %v", err))
+			}
+		}()
+		defer wg.Done()
+
+		reg := lookupRegistration(domain)
+		evaluateExpiry(&reg, time.Now())
+		if len(reg.Warnings) > 0 {
+			log.Info(domain, " 注册信息告警: ", strings.Join(reg.Warnings, "; "))
+		}
+
+		regJson, _ := json.Marshal(reg)
+		regRecord := string(regJson)
+
+		// 结果储存回 redis
+		gfError := cs.HSetMap("whois:"+domain, map[string]string{
+			"REGISTRATION": regRecord,
+			"EXPIRY":       reg.Expiry,
+		})
+		if gfError != nil {
+			log.Error("存储whois结果失败: ", gfError.GetMsg())
+		}
+
+		newRecord := models.GfnCollectorLogWhois{
+			ID:           util.GenerateId(),
+			Name:         domain,
+			Source:       reg.Source,
+			CreatedDate:  reg.Created,
+			ExpiresDate:  reg.Expires,
+			DaysLeft:     reg.DaysLeft,
+			Expiry:       reg.Expiry,
+			Registration: &regRecord,
+			Status:       models.WhoisStatusSuccess,
+			CreateTime:   time.Now(),
+		}
+		if reg.Registrar != "" {
+			newRecord.Registrar = &reg.Registrar
+		}
+		switch reg.Error {
+		case "":
+		case errDomainNotFound.Error():
+			newRecord.Status = models.WhoisStatusNotFound
+		default:
+			newRecord.Status = models.WhoisStatusFailure
+		}
+
+		// 存数据库
+		daoErr := dao.GetWhoisDao().Add(&newRecord)
+		if daoErr != nil {
+			log.Error("添加Whois采集结果到数据库失败: ", daoErr.GetMsg())
+		}
+	}
+}
+
+// ============== 注册信息 - 查询和解析部分 ==============
+
+// 获取可注册域名, 如 www.example.co.uk -> example.co.uk
+func registrableDomain(name string) (string, error) {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	return publicsuffix.EffectiveTLDPlusOne(name)
+}
+
+// 优先 RDAP, 顶级域没有 RDAP 服务或查询失败时回退到 WHOIS
+func lookupRegistration(domain string) models.Registration {
+	tld := domain[strings.LastIndex(domain, ".")+1:]
+
+	var rdapErr error
+	if server := rdapServer(tld); server != "" {
+		reg, err := queryRDAP(domain, server)
+		if err == nil {
+			return reg
+		}
+		// 注册局明确返回不存在时不再回退
+		if errors.Is(err, errDomainNotFound) {
+			return models.Registration{Domain: domain, Source: models.SourceRDAP, Server: server, Error: err.Error()}
+		}
+		rdapErr = err
+		log.Error(domain, " RDAP 查询失败, 回退到 WHOIS: ", err)
+	}
+
+	reg, err := queryWhois(domain, tld)
+	if err != nil {
+		reg.Error = err.Error()
+		if rdapErr != nil && !errors.Is(err, errDomainNotFound) {
+			reg.Error = "rdap: " + rdapErr.Error() + "; whois: " + err.Error()
+		}
+	}
+	return reg
+}
+
+// 计算剩余天数并生成告警
+func evaluateExpiry(reg *models.Registration, now time.Time) {
+	reg.Warnings = []string{}
+	if reg.Status == nil {
+		reg.Status = []string{}
+	}
+	if reg.Nameservers == nil {
+		reg.Nameservers = []string{}
+	}
+	sort.Strings(reg.Nameservers)
+
+	switch {
+	case reg.Error != "":
+		reg.Expiry = models.ExpiryUnknown
+	case reg.Expires == nil:
+		reg.Expiry = models.ExpiryUnknown
+		reg.Warnings = append(reg.Warnings, "expiry date not found")
+	default:
+		days := int(math.Floor(reg.Expires.Sub(now).Hours() / 24))
+		reg.DaysLeft = &days
+		switch {
+		case days < 0:
+			reg.Expiry = models.ExpiryExpired
+			reg.Warnings = append(reg.Warnings, fmt.Sprintf("domain expired %d days ago", -days))
+		case days < env.GetServerConfig().Collector.Whois.ExpiryWarn:
+			reg.Expiry = models.ExpiryExpiring
+			reg.Warnings = append(reg.Warnings, fmt.Sprintf("domain expires in %d days", days))
+		default:
+			reg.Expiry = models.ExpiryOK
+		}
+	}
+
+	for _, status := range reg.Status {
+		if models.HoldStatuses[normalizeStatus(status)] {
+			reg.Warnings = append(reg.Warnings, "registry status "+status)
+		}
+	}
+}
+
+// RDAP 用 "client hold", EPP / WHOIS 用 "clientHold", 统一后再比较
+func normalizeStatus(status string) string {
+	return strings.ReplaceAll(strings.ToLower(status), " ", "")
+}
@@ -0,0 +1,16 @@
+package models
+
+/*
+ * @Desc: 缓存统计模型
+ * @author: bsyz
+ * @version: v1.0.0
+ */
+
+// 缓存命中统计
+type CacheStats struct {
+	Size      int    `json:"size"`      // 当前条目数
+	Capacity  int    `json:"capacity"`  // 容量
+	Hits      uint64 `json:"hits"`      // 命中次数
+	Misses    uint64 `json:"misses"`    // 未命中次数, 含已过期
+	Evictions uint64 `json:"evictions"` // 容量满时淘汰的条目数
+}
@@ -0,0 +1,26 @@
+package models
+
+/*
+ * @Desc: IP 归属地模型
+ * @author: bsyz
+ * @version: v1.0.0
+ */
+
+import "time"
+
+// IP 归属地查询结果
+type GeoInfo struct {
+	Country  string `json:"country"`  // 国家
+	City     string `json:"city"`     // 城市
+	ASN      string `json:"asn"`      // ASN, 格式 AS13335 (Cloudflare, Inc.)
+	ASNumber uint   `json:"asNumber"` // ASN 编号
+	ISP      string `json:"isp"`      // ISP / ASN 组织名称
+}
+
+// GeoIP 数据库信息
+type GeoDBInfo struct {
+	Edition   string    `json:"edition"`   // 数据库版本 GeoLite2-City 等
+	Path      string    `json:"path"`      // 文件路径
+	BuildTime time.Time `json:"buildTime"` // 数据库构建时间
+	LoadTime  time.Time `json:"loadTime"`  // 加载时间
+}
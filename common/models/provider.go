@@ -0,0 +1,36 @@
+package models
+
+/*
+ * @Desc: 服务商模型
+ * @author: bsyz
+ * @version: v1.0.0
+ */
+
+// 服务商分类
+const (
+	ProviderCDN     = "CDN"
+	ProviderCloud   = "Cloud"
+	ProviderHosting = "Hosting"
+	ProviderOrigin  = "Origin" // 未识别出服务商, 视为源站
+)
+
+// 服务商规则, 从数据文件加载
+type ProviderRule struct {
+	Name     string   `yaml:"name"`     // 服务商名称
+	Category string   `yaml:"category"` // 分类 CDN / Cloud / Hosting
+	ASNs     []uint   `yaml:"asns"`     // ASN 编号
+	CIDRs    []string `yaml:"cidrs"`    // IP 段
+	CNAMEs   []string `yaml:"cnames"`   // CNAME 后缀, 可写作 *.akamaiedge.net
+}
+
+// 服务商规则文件
+type ProviderRuleFile struct {
+	Providers []ProviderRule `yaml:"providers"`
+}
+
+// ProviderMatch 服务商识别结果
+type ProviderMatch struct {
+	Name     string // 服务商名称, 未识别时为空
+	Category string // 分类, 未识别时为 Origin
+	Evidence string // 命中依据, 如 cname xxx.akamaiedge.net / ip 104.16.0.0/13 / asn 13335
+}
@@ -0,0 +1,207 @@
+package service
+
+/*
+ * @Desc: GeoIP服务
+ * @author: bsyz
+ * @version: v1.0.0
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/common"
+	"github.com/GoFurry/gofurry-nav-collector/common/log"
+	"github.com/GoFurry/gofurry-nav-collector/common/models"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"github.com/oschwald/geoip2-golang"
+)
+
+// GeoLite2 数据库版本
+const (
+	GeoEditionCountry = "GeoLite2-Country"
+	GeoEditionCity    = "GeoLite2-City"
+	GeoEditionASN     = "GeoLite2-ASN"
+)
+
+var GeoEditions = []string{GeoEditionCountry, GeoEditionCity, GeoEditionASN}
+
+// 单个已加载的数据库
+type geoDB struct {
+	reader  *geoip2.Reader
+	info    models.GeoDBInfo
+	modTime time.Time
+}
+
+// 全部数据库, 整体原子替换
+type geoDBSet map[string]*geoDB
+
+var geoDBs atomic.Pointer[geoDBSet]
+var geoReloadLock sync.Mutex
+
+// 查询期间持有读锁, 关闭旧数据库前取写锁, 保证没有查询还在读取已解除映射的文件
+var geoLookupLock sync.RWMutex
+
+func InitGeoIPOnStart() {
+	if err := ReloadGeoIP(); err != nil {
+		log.Error(err.GetMsg())
+	}
+	interval := env.GetServerConfig().Collector.GeoIP.ReloadInterval
+	if interval <= 0 {
+		interval = 60
+	}
+	AddCronJob(time.Duration(interval)*time.Second, func() {
+		if err := ReloadGeoIP(); err != nil {
+			log.Error(err.GetMsg())
+		}
+	})
+	// 定时下载更新
+	initGeoIPUpdate()
+	log.Info("InitGeoIP finish")
+}
+
+// GeoIP 数据库目录
+func GetGeoIPPath() string {
+	if path := env.GetServerConfig().Collector.GeoIP.Path; path != "" {
+		return path
+	}
+	// 兼容旧配置
+	return env.GetServerConfig().Collector.Dns.Geolite2Path
+}
+
+// 重新加载磁盘上有变化的数据库, 未变化的继续使用
+func ReloadGeoIP() common.GFError {
+	geoReloadLock.Lock()
+	defer geoReloadLock.Unlock()
+
+	current := geoDBs.Load()
+	next := make(geoDBSet)
+	var replaced []*geoDB
+	var failed []string
+	changed := current == nil
+
+	for _, edition := range GeoEditions {
+		path := filepath.Join(GetGeoIPPath(), edition+".mmdb")
+		var old *geoDB
+		if current != nil {
+			old = (*current)[edition]
+		}
+
+		stat, err := os.Stat(path)
+		if err != nil {
+			failed = append(failed, edition+": "+err.Error())
+			if old != nil {
+				next[edition] = old
+			}
+			continue
+		}
+		if old != nil && old.modTime.Equal(stat.ModTime()) {
+			next[edition] = old
+			continue
+		}
+
+		reader, err := geoip2.Open(path)
+		if err != nil {
+			failed = append(failed, edition+": "+err.Error())
+			if old != nil {
+				next[edition] = old
+			}
+			continue
+		}
+		next[edition] = &geoDB{
+			reader:  reader,
+			modTime: stat.ModTime(),
+			info: models.GeoDBInfo{
+				Edition:   edition,
+				Path:      path,
+				BuildTime: time.Unix(int64(reader.Metadata().BuildEpoch), 0),
+				LoadTime:  time.Now(),
+			},
+		}
+		changed = true
+		if old != nil {
+			replaced = append(replaced, old)
+		}
+		log.Info(fmt.Sprintf("GeoIP 数据库 %s 已加载, 构建时间 %s", edition, next[edition].info.BuildTime.Format(common.TIME_FORMAT_DATE)))
+	}
+
+	if changed {
+		geoDBs.Store(&next)
+		// 数据库信息存 redis, 便于查看数据新旧
+		if client != nil {
+			if infoJson, jsonErr := json.Marshal(GetGeoIPInfo()); jsonErr == nil {
+				Set("geoip:info", string(infoJson))
+			}
+		}
+	}
+	if len(replaced) > 0 {
+		// 新集合已发布, 之后的查询不会再拿到旧数据库, 等进行中的查询结束后关闭
+		geoLookupLock.Lock()
+		for _, db := range replaced {
+			db.reader.Close()
+		}
+		geoLookupLock.Unlock()
+	}
+	if len(failed) > 0 {
+		return common.NewServiceError(fmt.Sprintf("加载 GeoIP 数据库失败: %v", failed))
+	}
+	return nil
+}
+
+// 已加载数据库的构建时间等信息
+func GetGeoIPInfo() []models.GeoDBInfo {
+	res := []models.GeoDBInfo{}
+	set := geoDBs.Load()
+	if set == nil {
+		return res
+	}
+	for _, edition := range GeoEditions {
+		if db, ok := (*set)[edition]; ok {
+			res = append(res, db.info)
+		}
+	}
+	return res
+}
+
+// 查询 IP 的国家、城市、ASN 和 ISP 信息, 查不到的字段为 Unknown
+func LookupGeoIP(ip net.IP) models.GeoInfo {
+	res := models.GeoInfo{Country: "Unknown", City: "Unknown", ASN: "Unknown", ISP: "Unknown"}
+	geoLookupLock.RLock()
+	defer geoLookupLock.RUnlock()
+	set := geoDBs.Load()
+	if set == nil || ip == nil {
+		return res
+	}
+
+	if db, ok := (*set)[GeoEditionCountry]; ok {
+		if rec, err := db.reader.Country(ip); err == nil {
+			if n, ok := rec.Country.Names["en"]; ok {
+				res.Country = n
+			}
+		}
+	}
+	if db, ok := (*set)[GeoEditionCity]; ok {
+		if rec, err := db.reader.City(ip); err == nil {
+			if n := rec.City.Names["en"]; n != "" {
+				res.City = n
+			}
+			if n, ok := rec.Country.Names["en"]; ok {
+				res.Country = n
+			}
+		}
+	}
+	if db, ok := (*set)[GeoEditionASN]; ok {
+		if rec, err := db.reader.ASN(ip); err == nil {
+			res.ASNumber = rec.AutonomousSystemNumber
+			res.ASN = fmt.Sprintf("AS%d (%s)", rec.AutonomousSystemNumber, rec.AutonomousSystemOrganization)
+			res.ISP = rec.AutonomousSystemOrganization
+		}
+	}
+	return res
+}
@@ -0,0 +1,259 @@
+package service
+
+/*
+ * @Desc: GeoIP数据库更新服务
+ * @author: bsyz
+ * @version: v1.0.0
+ */
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/common"
+	"github.com/GoFurry/gofurry-nav-collector/common/log"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"github.com/oschwald/geoip2-golang"
+)
+
+// 单个数据库压缩包大小上限
+const geoMaxArchiveSize = 512 << 20
+
+// 初始化定时更新
+func initGeoIPUpdate() {
+	conf := env.GetServerConfig().Collector.GeoIP
+	if conf.UpdateInterval <= 0 || conf.LicenseKey == "" {
+		log.Info("GeoIP 自动更新未开启")
+		return
+	}
+	// 启动时缺少数据库则立即下载
+	for _, edition := range GeoEditions {
+		if !isGeoEditionLoaded(edition) {
+			go UpdateGeoIP()
+			break
+		}
+	}
+	AddCronJob(time.Duration(conf.UpdateInterval)*time.Hour, UpdateGeoIP)
+}
+
+// 下载并替换全部 GeoLite2 数据库, 成功后重新加载
+func UpdateGeoIP() {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error(fmt.Sprintf("receive UpdateGeoIP recover: %v", err))
+		}
+	}()
+
+	log.Info("GeoIP 数据库更新开始")
+	var updated []string
+	for _, edition := range GeoEditions {
+		changed, err := updateGeoEdition(edition)
+		if err != nil {
+			log.Error(edition+" 更新失败: ", err.GetMsg())
+			continue
+		}
+		if changed {
+			updated = append(updated, edition)
+		}
+	}
+	if len(updated) == 0 {
+		log.Info("GeoIP 数据库无更新")
+		return
+	}
+
+	// 重新加载, 失败的版本回滚到上一份
+	if err := ReloadGeoIP(); err != nil {
+		log.Error(err.GetMsg())
+		for _, edition := range updated {
+			if !isGeoEditionLoaded(edition) {
+				if rollbackErr := RollbackGeoIP(edition); rollbackErr != nil {
+					log.Error(rollbackErr.GetMsg())
+				}
+			}
+		}
+	}
+	log.Info("GeoIP 数据库更新结束: ", strings.Join(updated, ","))
+}
+
+// 回滚到上一份数据库并重新加载
+func RollbackGeoIP(edition string) common.GFError {
+	path := filepath.Join(GetGeoIPPath(), edition+".mmdb")
+	if _, err := os.Stat(path + ".bak"); err != nil {
+		return common.NewServiceError(edition + " 没有可回滚的版本")
+	}
+	if err := os.Rename(path+".bak", path); err != nil {
+		return common.NewServiceError(edition + " 回滚失败: " + err.Error())
+	}
+	// 清除校验记录, 下次更新时重新下载
+	_ = os.Remove(path + ".sha256")
+	log.Warn(edition + " 已回滚到上一份数据库")
+	return ReloadGeoIP()
+}
+
+// 判断某个版本当前是否由最新文件加载
+func isGeoEditionLoaded(edition string) bool {
+	set := geoDBs.Load()
+	if set == nil {
+		return false
+	}
+	db, ok := (*set)[edition]
+	if !ok {
+		return false
+	}
+	stat, err := os.Stat(db.info.Path)
+	return err == nil && stat.ModTime().Equal(db.modTime)
+}
+
+// 下载地址 {base_url}/geoip/databases/{edition}/download?suffix=tar.gz
+func geoDownloadURL(edition string, suffix string) string {
+	base := strings.TrimSuffix(env.GetServerConfig().Collector.GeoIP.BaseURL, "/")
+	if base == "" {
+		base = "https://download.maxmind.com"
+	}
+	return fmt.Sprintf("%s/geoip/databases/%s/download?suffix=%s", base, url.PathEscape(edition), url.QueryEscape(suffix))
+}
+
+// 带授权的 GET 请求
+func geoDownload(target string, w io.Writer, limit int64) common.GFError {
+	conf := env.GetServerConfig().Collector.GeoIP
+	transport := &http.Transport{}
+	if conf.UseProxy && env.GetServerConfig().Collector.Proxy != "" {
+		proxyURL, _ := url.Parse(env.GetServerConfig().Collector.Proxy)
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	client := &http.Client{Transport: transport, Timeout: 10 * time.Minute}
+
+	req, err := http.NewRequest("GET", target, nil)
+	if err != nil {
+		return common.NewServiceError("创建请求失败: " + err.Error())
+	}
+	req.SetBasicAuth(conf.AccountID, conf.LicenseKey)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return common.NewServiceError("下载失败: " + err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return common.NewServiceError(fmt.Sprintf("下载失败: %s 返回 %d", target, resp.StatusCode))
+	}
+	if _, err = io.Copy(w, io.LimitReader(resp.Body, limit)); err != nil {
+		return common.NewServiceError("下载失败: " + err.Error())
+	}
+	return nil
+}
+
+// 下载、校验、解压并替换单个数据库, 旧文件保留为 .bak
+// 远端与本地 SHA256 一致时不下载, 返回 false
+func updateGeoEdition(edition string) (bool, common.GFError) {
+	dir := GetGeoIPPath()
+	path := filepath.Join(dir, edition+".mmdb")
+
+	// 下载 SHA256, 格式: <hex>  <文件名>
+	var sumBuf strings.Builder
+	if err := geoDownload(geoDownloadURL(edition, "tar.gz.sha256"), &sumBuf, 4096); err != nil {
+		return false, err
+	}
+	fields := strings.Fields(sumBuf.String())
+	if len(fields) == 0 {
+		return false, common.NewServiceError("SHA256 内容为空")
+	}
+	expected := strings.ToLower(fields[0])
+
+	// 已是最新版本时跳过
+	if last, err := os.ReadFile(path + ".sha256"); err == nil && strings.TrimSpace(string(last)) == expected {
+		return false, nil
+	}
+
+	// 下载压缩包到临时文件, 同时计算 SHA256
+	archive, err := os.CreateTemp(dir, edition+"-*.tar.gz")
+	if err != nil {
+		return false, common.NewServiceError("创建临时文件失败: " + err.Error())
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	hash := sha256.New()
+	if gfErr := geoDownload(geoDownloadURL(edition, "tar.gz"), io.MultiWriter(archive, hash), geoMaxArchiveSize); gfErr != nil {
+		return false, gfErr
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return false, common.NewServiceError(fmt.Sprintf("SHA256 校验失败: 期望 %s 实际 %s", expected, actual))
+	}
+
+	// 解压出 mmdb
+	if _, err = archive.Seek(0, io.SeekStart); err != nil {
+		return false, common.NewServiceError("读取压缩包失败: " + err.Error())
+	}
+	tmpPath := path + ".tmp"
+	if gfErr := extractGeoArchive(archive, edition+".mmdb", tmpPath); gfErr != nil {
+		os.Remove(tmpPath)
+		return false, gfErr
+	}
+
+	// 校验新文件可以正常打开
+	reader, err := geoip2.Open(tmpPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return false, common.NewServiceError("新数据库无法打开: " + err.Error())
+	}
+	reader.Close()
+
+	// 保留上一份用于回滚, 再替换
+	if _, err = os.Stat(path); err == nil {
+		if err = os.Rename(path, path+".bak"); err != nil {
+			os.Remove(tmpPath)
+			return false, common.NewServiceError("备份旧数据库失败: " + err.Error())
+		}
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		_ = os.Rename(path+".bak", path)
+		return false, common.NewServiceError("替换数据库失败: " + err.Error())
+	}
+	_ = os.WriteFile(path+".sha256", []byte(expected), 0644)
+	log.Info(edition + " 已更新")
+	return true, nil
+}
+
+// 从 tar.gz 中解压指定文件
+func extractGeoArchive(r io.Reader, name string, target string) common.GFError {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return common.NewServiceError("解压失败: " + err.Error())
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return common.NewServiceError("压缩包中未找到 " + name)
+		}
+		if err != nil {
+			return common.NewServiceError("解压失败: " + err.Error())
+		}
+		if header.Typeflag != tar.TypeReg || filepath.Base(header.Name) != name {
+			continue
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return common.NewServiceError("写入数据库失败: " + err.Error())
+		}
+		_, err = io.Copy(out, io.LimitReader(tr, geoMaxArchiveSize))
+		closeErr := out.Close()
+		if err != nil || closeErr != nil {
+			return common.NewServiceError(fmt.Sprintf("写入数据库失败: %v %v", err, closeErr))
+		}
+		return nil
+	}
+}
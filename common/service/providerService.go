@@ -0,0 +1,149 @@
+package service
+
+/*
+ * @Desc: 服务商识别服务, DNS 和 HTTP 采集共用
+ * @author: bsyz
+ * @version: v1.0.0
+ */
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/common"
+	"github.com/GoFurry/gofurry-nav-collector/common/log"
+	"github.com/GoFurry/gofurry-nav-collector/common/models"
+	"github.com/GoFurry/gofurry-nav-collector/roof/env"
+	"gopkg.in/yaml.v2"
+)
+
+// 编译后的服务商数据
+type providerIndex struct {
+	prefixes []providerPrefix              // 按前缀长度降序, 先命中更具体的段
+	asns     map[uint]*models.ProviderRule // ASN -> 服务商
+	suffixes []providerSuffix              // 按长度降序, 先命中更具体的后缀
+}
+
+type providerPrefix struct {
+	block *net.IPNet
+	ones  int
+	rule  *models.ProviderRule
+}
+
+type providerSuffix struct {
+	suffix string
+	rule   *models.ProviderRule
+}
+
+// 服务商数据 文件更新后自动重新加载
+var providers = &providerIndex{asns: map[uint]*models.ProviderRule{}}
+var providerModTime time.Time
+var providerRWLock sync.RWMutex
+
+// 加载服务商数据, 文件未变化时跳过
+func LoadProviderRules() common.GFError {
+	path := env.GetServerConfig().Collector.Dns.ProviderPath
+	if path == "" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return common.NewServiceError("读取服务商数据文件失败: " + err.Error())
+	}
+
+	providerRWLock.RLock()
+	unchanged := info.ModTime().Equal(providerModTime)
+	providerRWLock.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return common.NewServiceError("读取服务商数据文件失败: " + err.Error())
+	}
+	var ruleFile models.ProviderRuleFile
+	if err = yaml.Unmarshal(fileBytes, &ruleFile); err != nil {
+		return common.NewServiceError("解析服务商数据文件失败: " + err.Error())
+	}
+	index := compileProviders(ruleFile.Providers)
+
+	providerRWLock.Lock()
+	providers = index
+	providerModTime = info.ModTime()
+	providerRWLock.Unlock()
+
+	log.Info(fmt.Sprintf("服务商数据加载完成, 共 %d 个服务商, %d 个 IP 段, %d 个 ASN, %d 个 CNAME 后缀",
+		len(ruleFile.Providers), len(index.prefixes), len(index.asns), len(index.suffixes)))
+	return nil
+}
+
+// 编译服务商规则
+func compileProviders(rules []models.ProviderRule) *providerIndex {
+	index := &providerIndex{asns: make(map[uint]*models.ProviderRule)}
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Category == "" {
+			rule.Category = models.ProviderCDN
+		}
+		for _, cidr := range rule.CIDRs {
+			_, block, err := net.ParseCIDR(cidr)
+			if err != nil {
+				log.Warn(rule.Name + " IP 段无效: " + cidr)
+				continue
+			}
+			ones, _ := block.Mask.Size()
+			index.prefixes = append(index.prefixes, providerPrefix{block: block, ones: ones, rule: rule})
+		}
+		for _, asn := range rule.ASNs {
+			if other, ok := index.asns[asn]; ok {
+				log.Warn(fmt.Sprintf("ASN %d 同时属于 %s 和 %s, 使用前者", asn, other.Name, rule.Name))
+				continue
+			}
+			index.asns[asn] = rule
+		}
+		for _, cname := range rule.CNAMEs {
+			suffix := strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(cname)), "*."), ".")
+			if suffix == "" {
+				continue
+			}
+			index.suffixes = append(index.suffixes, providerSuffix{suffix: suffix, rule: rule})
+		}
+	}
+	sort.SliceStable(index.prefixes, func(i, j int) bool { return index.prefixes[i].ones > index.prefixes[j].ones })
+	sort.SliceStable(index.suffixes, func(i, j int) bool { return len(index.suffixes[i].suffix) > len(index.suffixes[j].suffix) })
+	return index
+}
+
+// 识别 IP 所属服务商
+// 依次匹配 CNAME 后缀、IP 段、ASN: CNAME 能区分同一云厂商下的 CDN 与主机, IP 段比 ASN 更具体
+// names 为解析链上的域名 (查询名和 CNAME 目标), 从链尾开始匹配, 最后一跳最接近实际提供服务的节点
+func DetectProvider(ip net.IP, asn uint, names []string) models.ProviderMatch {
+	providerRWLock.RLock()
+	index := providers
+	providerRWLock.RUnlock()
+
+	for i := len(names) - 1; i >= 0; i-- {
+		name := strings.TrimSuffix(strings.ToLower(names[i]), ".")
+		for _, s := range index.suffixes {
+			if name == s.suffix || strings.HasSuffix(name, "."+s.suffix) {
+				return models.ProviderMatch{Name: s.rule.Name, Category: s.rule.Category, Evidence: "cname " + name}
+			}
+		}
+	}
+	for _, p := range index.prefixes {
+		if p.block.Contains(ip) {
+			return models.ProviderMatch{Name: p.rule.Name, Category: p.rule.Category, Evidence: "ip " + p.block.String()}
+		}
+	}
+	if rule, ok := index.asns[asn]; ok && asn != 0 {
+		return models.ProviderMatch{Name: rule.Name, Category: rule.Category, Evidence: "asn " + strconv.FormatUint(uint64(asn), 10)}
+	}
+	return models.ProviderMatch{Category: models.ProviderOrigin}
+}
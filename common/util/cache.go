@@ -0,0 +1,121 @@
+package util
+
+/*
+ * @Desc: 带过期时间的 LRU 缓存
+ * @author: bsyz
+ * @version: v1.0.0
+ */
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-nav-collector/common/models"
+)
+
+// 默认容量
+const defaultCacheCapacity = 1024
+
+// 定长 LRU 缓存, 条目按各自的 TTL 过期, 并发安全
+type TTLCache[K comparable, V any] struct {
+	mu        sync.Mutex
+	capacity  int
+	ttl       time.Duration
+	items     map[K]*list.Element
+	order     *list.List // 队头为最近使用
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type cacheEntry[K comparable, V any] struct {
+	key    K
+	value  V
+	expire time.Time
+}
+
+// 创建缓存, capacity <= 0 时使用默认容量, ttl 为 Set 使用的默认过期时间
+func NewTTLCache[K comparable, V any](capacity int, ttl time.Duration) *TTLCache[K, V] {
+	if capacity <= 0 {
+		capacity = defaultCacheCapacity
+	}
+	return &TTLCache[K, V]{
+		capacity: capacity,
+		ttl:      ttl,
+		items:    make(map[K]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// 获取未过期的值, 过期的条目顺带删除
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*cacheEntry[K, V])
+		if time.Now().Before(entry.expire) {
+			c.order.MoveToFront(elem)
+			c.hits++
+			return entry.value, true
+		}
+		c.removeElement(elem)
+	}
+	c.misses++
+	var zero V
+	return zero, false
+}
+
+// 按默认过期时间写入
+func (c *TTLCache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// 按指定过期时间写入, ttl <= 0 时不缓存
+func (c *TTLCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expire := time.Now().Add(ttl)
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*cacheEntry[K, V])
+		entry.value, entry.expire = value, expire
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.order.PushFront(&cacheEntry[K, V]{key: key, value: value, expire: expire})
+	// 超出容量时淘汰最久未使用的条目
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+		c.evictions++
+	}
+}
+
+// 删除条目
+func (c *TTLCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// 命中统计
+func (c *TTLCache[K, V]) Stats() models.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return models.CacheStats{
+		Size:      c.order.Len(),
+		Capacity:  c.capacity,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+func (c *TTLCache[K, V]) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*cacheEntry[K, V]).key)
+}
@@ -70,6 +70,8 @@ func InitOnStart() {
 	cs.InitRedisOnStart()
 	// 初始化时间调度
 	cs.InitTimeWheelOnStart()
+	// 初始化 GeoIP 数据库
+	cs.InitGeoIPOnStart()
 }
 
 type goFurry struct{}
@@ -24,25 +24,126 @@ type serverConfig struct {
 }
 
 type CollectorConfig struct {
-	Proxy   string        `yaml:"proxy"`
-	Ping    PingConfig    `yaml:"ping"`
-	Request RequestConfig `yaml:"request"`
-	Dns     DnsConfig     `yaml:"dns"`
+	Proxy      string           `yaml:"proxy"`
+	Ping       PingConfig       `yaml:"ping"`
+	Request    RequestConfig    `yaml:"request"`
+	Dns        DnsConfig        `yaml:"dns"`
+	Whois      WhoisConfig      `yaml:"whois"`
+	Compliance ComplianceConfig `yaml:"compliance"`
+	GeoIP      GeoIPConfig      `yaml:"geoip"`
+}
+
+type GeoIPConfig struct {
+	Path           string `yaml:"path"`
+	ReloadInterval int    `yaml:"reload_interval"`
+	UpdateInterval int    `yaml:"update_interval"`
+	BaseURL        string `yaml:"base_url"`
+	AccountID      string `yaml:"account_id"`
+	LicenseKey     string `yaml:"license_key"`
+	UseProxy       bool   `yaml:"use_proxy"`
 }
 
 type DnsConfig struct {
 	DnsThread    int    `yaml:"dns_thread"`
 	QueryThread  int    `yaml:"query_thread"`
 	DnsInterval  int    `yaml:"dns_interval"`
-	Resolver     string `yaml:"resolver"`
-	Geolite2Path string `yaml:"geolite2_path"`
+	Resolver     string `yaml:"resolver"`      // 支持 udp:// tcp:// tls:// https:// 地址
+	Geolite2Path string `yaml:"geolite2_path"` // 已迁移到 geoip.path, 兼容旧配置
 	LogCount     string `yaml:"log_count"`
+
+	ResolverTimeout   int    `yaml:"resolver_timeout"`   // 查询超时 (秒)
+	ResolverSNI       string `yaml:"resolver_sni"`       // DoT / DoH 的 SNI, 默认取地址中的主机名
+	ResolverBootstrap string `yaml:"resolver_bootstrap"` // 引导 IP, 不再解析解析器主机名
+
+	DnssecExpiryWarn int  `yaml:"dnssec_expiry_warn"` // 签名剩余有效期少于该天数时告警
+	SkipUnchanged    bool `yaml:"skip_unchanged"`     // 与上次结果相同时不写入新的采集记录
+	CheckIPv6        bool `yaml:"check_ipv6"`         // 委派检查时是否查询权威服务器的 IPv6 地址
+
+	RecordTypes []string `yaml:"record_types"` // 采集的记录类型, 为空时使用默认的 8 种
+	SrvNames    []string `yaml:"srv_names"`    // SRV 查询的服务名前缀, 如 _minecraft._tcp
+
+	ProviderPath string `yaml:"provider_path"` // 服务商数据文件 (IP 段 / ASN / CNAME 后缀), HTTP 采集识别 CDN 时共用
+	BogonPath    string `yaml:"bogon_path"`    // 保留 / 未分配地址段数据文件
+
+	GeoCacheSize   int `yaml:"geo_cache_size"`   // GeoIP 缓存条数
+	GeoCacheTTL    int `yaml:"geo_cache_ttl"`    // GeoIP 缓存时间 (小时)
+	PtrCacheSize   int `yaml:"ptr_cache_size"`   // PTR 缓存条数, 按记录 TTL 过期
+	PtrNegativeTTL int `yaml:"ptr_negative_ttl"` // 没有 PTR 或查询失败时的缓存时间 (秒)
+
+	MailCheck     bool     `yaml:"mail_check"`     // 是否检查 SPF / DMARC / DKIM / MTA-STS / TLS-RPT
+	DkimSelectors []string `yaml:"dkim_selectors"` // 尝试的 DKIM selector, 为空时使用内置列表
+
+	TakeoverCheck bool `yaml:"takeover_check"` // 检查 CNAME 是否指向已释放的服务 (子域名接管)
+	AuditCheck    bool `yaml:"audit_check"`    // 安全审计: 区域传送 / 开放递归 / 泛解析 / CAA
+
+	EcsResolver ResolverConfig    `yaml:"ecs_resolver"` // 发送 ECS 的解析器, 需支持 ECS, 为空时使用默认解析器
+	EcsSubnets  []EcsSubnetConfig `yaml:"ecs_subnets"`  // 模拟的客户端网段, 为空时不做 ECS 查询
+
+	DnsblZones    []DnsblConfig  `yaml:"dnsbl_zones"`     // IP 黑名单, 为空时不检查
+	DnsblResolver ResolverConfig `yaml:"dnsbl_resolver"`  // 查询黑名单的解析器, 为空时使用默认解析器
+	DnsblCacheTTL int            `yaml:"dnsbl_cache_ttl"` // 同一 IP 的检查结果缓存时间 (分钟)
+
+	HijackBaseline  ResolverConfig `yaml:"hijack_baseline"`  // 劫持检测的可信解析器, 建议使用 DoH / DoT
+	HijackThreshold int            `yaml:"hijack_threshold"` // 劫持评分达到该值时标记为劫持
+
+	Resolvers            []ResolverConfig `yaml:"resolvers"`             // 多解析器对比
+	CompareAuthoritative bool             `yaml:"compare_authoritative"` // 对比时是否加入权威服务器
+}
+
+type ResolverConfig struct {
+	Name      string `yaml:"name"`
+	Address   string `yaml:"address"` // 支持 udp:// tcp:// tls:// https:// 地址
+	Group     string `yaml:"group"`   // foreign domestic
+	Timeout   int    `yaml:"timeout"` // 查询超时 (秒)
+	SNI       string `yaml:"sni"`
+	Bootstrap string `yaml:"bootstrap"`
+}
+
+type WhoisConfig struct {
+	WhoisThread   int    `yaml:"whois_thread"`
+	WhoisInterval int    `yaml:"whois_interval"` // 采集间隔 (小时)
+	LogCount      string `yaml:"log_count"`
+	Timeout       int    `yaml:"timeout"`     // 单次查询超时 (秒)
+	ExpiryWarn    int    `yaml:"expiry_warn"` // 剩余天数少于该值时告警
+	UseProxy      bool   `yaml:"use_proxy"`   // RDAP 查询是否走 collector.proxy
+
+	RdapBootstrap string            `yaml:"rdap_bootstrap"` // RDAP 引导文件地址 (IANA dns.json 格式)
+	RdapServers   map[string]string `yaml:"rdap_servers"`   // 按顶级域指定 RDAP 服务地址, 优先于引导文件
+	WhoisRefer    string            `yaml:"whois_refer"`    // 查找顶级域 WHOIS 服务器的引导服务器
+	WhoisServers  map[string]string `yaml:"whois_servers"`  // 按顶级域指定 WHOIS 服务器, 优先于引导服务器
+}
+
+type ComplianceConfig struct {
+	ComplianceInterval int              `yaml:"compliance_interval"` // 检查间隔 (小时)
+	LogCount           string           `yaml:"log_count"`
+	CAIssuers          []CAIssuerConfig `yaml:"ca_issuers"` // 补充的签发机构, 优先于内置列表
+}
+
+type CAIssuerConfig struct {
+	Name     string   `yaml:"name"`
+	Keywords []string `yaml:"keywords"` // 在证书 Issuer 的 CN / O 中匹配的关键字
+	Domains  []string `yaml:"domains"`  // CA 认可的 CAA issuer 域名
+}
+
+type DnsblConfig struct {
+	Name string `yaml:"name"`
+	Zone string `yaml:"zone"` // 查询域, 如 zen.spamhaus.org
+	IPv6 bool   `yaml:"ipv6"` // 是否支持 IPv6 地址
+}
+
+type EcsSubnetConfig struct {
+	Name    string `yaml:"name"`
+	Subnet  string `yaml:"subnet"`  // 客户端网段, 如 202.96.128.0/24
+	Country string `yaml:"country"` // 网段所在国家, 为空时按 GeoIP 判断
 }
 
 type RequestConfig struct {
-	RequestThread   int    `yaml:"request_thread"`
-	RequestInterval int    `yaml:"request_interval"`
-	LogCount        string `yaml:"log_count"`
+	RequestThread     int    `yaml:"request_thread"`
+	RequestInterval   int    `yaml:"request_interval"`
+	LogCount          string `yaml:"log_count"`
+	FingerprintPath   string `yaml:"fingerprint_path"`
+	DiscoveryInterval int    `yaml:"discovery_interval"`
+	DiscoveryApply    bool   `yaml:"discovery_apply"`
 }
 
 type PingConfig struct {
@@ -1,9 +1,11 @@
 package schedule
 
 import (
+	complianceService "github.com/GoFurry/gofurry-nav-collector/collector/compliance/service"
 	dnsService "github.com/GoFurry/gofurry-nav-collector/collector/dns/service"
 	httpService "github.com/GoFurry/gofurry-nav-collector/collector/http/service"
 	pingService "github.com/GoFurry/gofurry-nav-collector/collector/ping/service"
+	whoisService "github.com/GoFurry/gofurry-nav-collector/collector/whois/service"
 	"github.com/GoFurry/gofurry-nav-collector/common/log"
 )
 
@@ -14,7 +16,9 @@ func InitSchedule() {
 		}
 	}()
 
-	pingService.InitPingOnStart() // ping
-	httpService.InitHTTPOnStart() // http
-	dnsService.InitDNSOnStart()   // dns
+	pingService.InitPingOnStart()             // ping
+	httpService.InitHTTPOnStart()             // http
+	dnsService.InitDNSOnStart()               // dns
+	whoisService.InitWhoisOnStart()           // whois
+	complianceService.InitComplianceOnStart() // CAA 与证书签发机构交叉检查
 }